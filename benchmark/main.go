@@ -14,7 +14,10 @@ import (
 )
 
 var engine = flag.String("engine", "vm", "use 'vm' or 'eval'")
-var input = `
+var programName = flag.String("program", "fibonacci", "use 'fibonacci' or 'arrays'")
+
+var programs = map[string]string{
+	"fibonacci": `
 	let fibonacci = fn(x) {
 		if (x == 0) {
 			0
@@ -27,13 +30,30 @@ var input = `
 		}
 	};
 	fibonacci(30);
-`
+`,
+	// Builds an array of 100k elements with push, then sums it with head and tail (the recursive style)
+	"arrays": `
+	let build = fn(array, n) {
+		if (n == 0) { array } else { build(push(array, n), n - 1) }
+	};
+	let sum = fn(array, acc) {
+		if (len(array) == 0) { acc } else { sum(tail(array), acc + head(array)) }
+	};
+	sum(build([], 100000), 0);
+`,
+}
 
 func main() {
 	flag.Parse()
 	var duration time.Duration
 	var result data.Data
 
+	input, ok := programs[*programName]
+	if !ok {
+		fmt.Printf("unknown program: %s\n", *programName)
+		return
+	}
+
 	l := lexer.New(input)
 	p := parser.New(l)
 
@@ -63,5 +83,5 @@ func main() {
 		duration = time.Since(start)
 	}
 
-	fmt.Printf("engine=%s, result=%s, duration=%s\n", *engine, result.Inspect(), duration)
+	fmt.Printf("engine=%s, program=%s, result=%s, duration=%s\n", *engine, *programName, result.Inspect(), duration)
 }
@@ -1 +1,3 @@
 module github.com/ape-lang/ape
+
+go 1.27.1
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ape-lang/ape/src/ape"
+	"github.com/ape-lang/ape/src/compiler/vm"
+	"github.com/ape-lang/ape/src/data"
+)
+
+func main() {
+	for _, n := range []int{1000, 10000} {
+		cfg := vm.DefaultConfig
+		cfg.Limits = data.Limits{Allocations: 1000000}
+		r := ape.NewRuntimeWithConfig(cfg)
+		_, err := r.Run(fmt.Sprintf(`let a = []; let i = 0; while (i < %d) { a = push(a, i); i = i + 1 }; len(a)`, n))
+		fmt.Println(n, err)
+	}
+}
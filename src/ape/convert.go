@@ -0,0 +1,371 @@
+package ape
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+var dataType = reflect.TypeOf((*data.Data)(nil)).Elem()
+
+// ToData converts a Go value into an Ape value
+// Numbers, strings and booleans become their Ape counterparts, slices and arrays become arrays, maps and structs become hashes
+// (struct fields are named by their `ape` tag, "-" skipping them), functions become builtins and nils become null
+func ToData(value interface{}) (data.Data, error) {
+	return toData(reflect.ValueOf(value))
+}
+
+func toData(v reflect.Value) (data.Data, error) {
+	if !v.IsValid() {
+		return data.NULL, nil
+	}
+	if v.Type().Implements(dataType) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return data.NULL, nil
+		}
+		return v.Interface().(data.Data), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return data.TRUE, nil
+		}
+		return data.FALSE, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &data.Integer{Value: v.Int()}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d overflows an integer", v.Uint())
+		}
+		return &data.Integer{Value: int64(v.Uint())}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return &data.Float{Value: v.Float()}, nil
+
+	case reflect.String:
+		return &data.String{Value: v.String()}, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return data.NULL, nil
+		}
+		elements := make([]data.Data, v.Len())
+		for i := range elements {
+			element, err := toData(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return data.NewArray(elements), nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return data.NULL, nil
+		}
+		type entry struct {
+			key   data.Data
+			value reflect.Value
+		}
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := toData(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{key: key, value: iter.Value()})
+		}
+		// Go maps have no order, so the keys are sorted to keep the hash deterministic
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key.Inspect() < entries[j].key.Inspect() })
+
+		hash := data.NewHash(len(entries))
+		for _, e := range entries {
+			err := setPair(hash, e.key, e.value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return hash, nil
+
+	case reflect.Struct:
+		hash := data.NewHash(v.NumField())
+		for _, field := range fields(v.Type()) {
+			err := setPair(hash, &data.String{Value: field.name}, v.Field(field.index))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return hash, nil
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return data.NULL, nil
+		}
+		return toData(v.Elem())
+
+	case reflect.Func:
+		if v.IsNil() {
+			return data.NULL, nil
+		}
+		return hostFunction(v)
+	}
+
+	return nil, fmt.Errorf("unsupported type: %s", v.Type())
+}
+
+func setPair(hash *data.Hash, key data.Data, value reflect.Value) error {
+	hashable, ok := key.(data.HashableData)
+	if !ok {
+		return fmt.Errorf("unusable as hash key: %s", key.Type())
+	}
+
+	val, err := toData(value)
+	if err != nil {
+		return err
+	}
+	hash.Set(data.HashData(hashable), data.HashPair{Key: key, Value: val})
+	return nil
+}
+
+// FromData converts an Ape value into the Go value target points to, following the rules of ToData backwards
+// Into an empty interface, values convert to int64, float64, string, bool, nil, []interface{} and
+// map[string]interface{} (map[interface{}]interface{} for hashes with other keys), other values are kept as is
+func FromData(d data.Data, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("the target must be a non-nil pointer, got %T", target)
+	}
+	return fromData(d, v.Elem())
+}
+
+func fromData(d data.Data, v reflect.Value) error {
+	if d == nil {
+		d = data.NULL
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			if value := toGo(d); value != nil {
+				v.Set(reflect.ValueOf(value))
+			} else {
+				v.Set(reflect.Zero(v.Type()))
+			}
+			return nil
+		}
+		if reflect.TypeOf(d).AssignableTo(v.Type()) {
+			v.Set(reflect.ValueOf(d))
+			return nil
+		}
+
+	case reflect.Ptr:
+		if d == data.NULL {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if reflect.TypeOf(d).AssignableTo(v.Type()) {
+			v.Set(reflect.ValueOf(d))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		err := fromData(d, elem.Elem())
+		if err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+
+	case reflect.Bool:
+		if b, ok := d.(*data.Boolean); ok {
+			v.SetBool(b.Value)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := d.(*data.Integer); ok {
+			if v.OverflowInt(i.Value) {
+				return fmt.Errorf("%d overflows %s", i.Value, v.Type())
+			}
+			v.SetInt(i.Value)
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if i, ok := d.(*data.Integer); ok {
+			if i.Value < 0 || v.OverflowUint(uint64(i.Value)) {
+				return fmt.Errorf("%d overflows %s", i.Value, v.Type())
+			}
+			v.SetUint(uint64(i.Value))
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		switch n := d.(type) {
+		case *data.Float:
+			v.SetFloat(n.Value)
+			return nil
+		case *data.Integer:
+			v.SetFloat(float64(n.Value))
+			return nil
+		}
+
+	case reflect.String:
+		if s, ok := d.(*data.String); ok {
+			v.SetString(s.Value)
+			return nil
+		}
+
+	case reflect.Slice:
+		if d == data.NULL {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if array, ok := d.(*data.Array); ok {
+			elements := array.Elements()
+			slice := reflect.MakeSlice(v.Type(), len(elements), len(elements))
+			for i, element := range elements {
+				err := fromData(element, slice.Index(i))
+				if err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+			return nil
+		}
+
+	case reflect.Array:
+		if array, ok := d.(*data.Array); ok {
+			if array.Len() != v.Len() {
+				return fmt.Errorf("cannot convert an array of %d elements to %s", array.Len(), v.Type())
+			}
+			for i, element := range array.Elements() {
+				err := fromData(element, v.Index(i))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case reflect.Map:
+		if d == data.NULL {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if hash, ok := d.(*data.Hash); ok {
+			m := reflect.MakeMapWithSize(v.Type(), hash.Len())
+			for _, pair := range hash.Pairs() {
+				key := reflect.New(v.Type().Key()).Elem()
+				err := fromData(pair.Key, key)
+				if err != nil {
+					return err
+				}
+				value := reflect.New(v.Type().Elem()).Elem()
+				err = fromData(pair.Value, value)
+				if err != nil {
+					return err
+				}
+				m.SetMapIndex(key, value)
+			}
+			v.Set(m)
+			return nil
+		}
+
+	case reflect.Struct:
+		if hash, ok := d.(*data.Hash); ok {
+			for _, field := range fields(v.Type()) {
+				key := &data.String{Value: field.name}
+				pair, ok := hash.Get(data.HashData(key))
+				if !ok {
+					continue
+				}
+				err := fromData(pair.Value, v.Field(field.index))
+				if err != nil {
+					return fmt.Errorf("field %s: %w", field.name, err)
+				}
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot convert %s to %s", d.Type(), v.Type())
+}
+
+// Converts an Ape value into its natural Go counterpart
+func toGo(d data.Data) interface{} {
+	switch d := d.(type) {
+	case *data.Null:
+		return nil
+	case *data.Boolean:
+		return d.Value
+	case *data.Integer:
+		return d.Value
+	case *data.Float:
+		return d.Value
+	case *data.String:
+		return d.Value
+	case *data.Array:
+		elements := make([]interface{}, d.Len())
+		for i, element := range d.Elements() {
+			elements[i] = toGo(element)
+		}
+		return elements
+	case *data.Hash:
+		named := map[string]interface{}{}
+		pairs := d.Pairs()
+		for _, pair := range pairs {
+			key, ok := pair.Key.(*data.String)
+			if !ok {
+				break
+			}
+			named[key.Value] = toGo(pair.Value)
+		}
+		if len(named) == len(pairs) {
+			return named
+		}
+
+		keyed := map[interface{}]interface{}{}
+		for _, pair := range pairs {
+			keyed[toGo(pair.Key)] = toGo(pair.Value)
+		}
+		return keyed
+	default:
+		return d
+	}
+}
+
+type field struct {
+	name  string
+	index int
+}
+
+// Returns the exported fields of a struct type with their names (the `ape` tag, or the field name)
+func fields(t reflect.Type) []field {
+	fields := []field{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("ape"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag, _, _ = strings.Cut(tag, ","); tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{name: name, index: i})
+	}
+	return fields
+}
@@ -0,0 +1,80 @@
+package ape
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Wraps a Go function into a builtin, converting its arguments from and its result to Ape values
+// The function returns at most one value, optionally followed by an error which is raised as an exception
+func hostFunction(fn reflect.Value) (*data.Builtin, error) {
+	if builtin, ok := fn.Interface().(func(args ...data.Data) data.Data); ok {
+		return &data.Builtin{Fn: builtin}, nil
+	}
+
+	t := fn.Type()
+	results := t.NumOut()
+	failable := results > 0 && t.Out(results-1) == errorType
+	if failable {
+		results--
+	}
+	if results > 1 {
+		return nil, fmt.Errorf("unsupported function %s: it returns more than one value (besides an error)", t)
+	}
+
+	params := t.NumIn()
+	if t.IsVariadic() {
+		params--
+	}
+
+	return &data.Builtin{Fn: func(args ...data.Data) data.Data {
+		if len(args) < params || (!t.IsVariadic() && len(args) > params) {
+			return raise(data.RuntimeErrorKind, fmt.Sprintf("wrong number of arguments: want=%d, got=%d", params, len(args)))
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			var paramType reflect.Type
+			if i < params {
+				paramType = t.In(i)
+			} else {
+				paramType = t.In(params).Elem()
+			}
+
+			in[i] = reflect.New(paramType).Elem()
+			err := fromData(arg, in[i])
+			if err != nil {
+				return raise(data.RuntimeErrorKind, fmt.Sprintf("argument %d: %s", i+1, err))
+			}
+		}
+
+		out := fn.Call(in)
+		if failable {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return raise(data.ErrorKind, err.Error())
+			}
+		}
+		if results == 0 {
+			return data.NULL
+		}
+
+		result, err := toData(out[0])
+		if err != nil {
+			return raise(data.RuntimeErrorKind, fmt.Sprintf("result: %s", err))
+		}
+		return result
+	}}, nil
+}
+
+// Raises an exception from a host function
+func raise(kind string, message string) *data.Error {
+	exception := &data.Exception{Kind: kind, Message: message}
+	if kind == data.ErrorKind {
+		exception.Value = &data.String{Value: message}
+	}
+	return &data.Error{Message: message, Exception: exception}
+}
@@ -0,0 +1,215 @@
+// Package ape embeds Ape in Go programs, exposing Go functions and values to Ape programs and back
+package ape
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ape-lang/ape/src/compiler/compiler"
+	"github.com/ape-lang/ape/src/compiler/symbols"
+	"github.com/ape-lang/ape/src/compiler/vm"
+	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/lexer"
+	"github.com/ape-lang/ape/src/parser"
+)
+
+// Runtime runs Ape programs one after the other, sharing their globals (like the REPL)
+// Host functions and values are set as globals, so each runtime has its own
+type Runtime struct {
+	symbols      *symbols.SymbolTable
+	constants    []data.Data
+	globals      []data.Data
+	config       vm.Config
+	optimization int
+	machine      *vm.VM // The VM running a program or a call (for the calls made by host functions)
+}
+
+// SyntaxError is returned when the source of a program can't be parsed
+type SyntaxError struct {
+	Source string
+	Errors []*parser.ParseError
+}
+
+func (e *SyntaxError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// NewRuntime creates a runtime running programs with the default VM configuration
+func NewRuntime() *Runtime {
+	return NewRuntimeWithConfig(vm.DefaultConfig)
+}
+
+// NewRuntimeWithConfig creates a runtime running programs with the given VM configuration (ex. to limit them)
+func NewRuntimeWithConfig(config vm.Config) *Runtime {
+	symbolTable := symbols.New()
+	for i, v := range data.Builtins {
+		symbolTable.DefineBuiltin(i, v.Name)
+	}
+
+	return &Runtime{
+		symbols:      symbolTable,
+		constants:    []data.Data{},
+		globals:      []data.Data{},
+		config:       config,
+		optimization: compiler.O1,
+	}
+}
+
+// Register exposes a Go function to the programs under the given name
+// Its arguments and result are converted like FromData and ToData do, and a non-nil error it returns is thrown
+func (rt *Runtime) Register(name string, fn interface{}) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("cannot register %T as a function", fn)
+	}
+
+	builtin, err := hostFunction(v)
+	if err != nil {
+		return err
+	}
+	rt.set(name, builtin)
+	return nil
+}
+
+// Set sets a global (converting the value like ToData does)
+func (rt *Runtime) Set(name string, value interface{}) error {
+	d, err := ToData(value)
+	if err != nil {
+		return err
+	}
+	rt.set(name, d)
+	return nil
+}
+
+func (rt *Runtime) set(name string, value data.Data) {
+	symbol, ok := rt.symbols.Resolve(name)
+	if !ok || symbol.Scope != symbols.GlobalScope {
+		symbol = rt.symbols.Define(name)
+	}
+	rt.growGlobals()
+	rt.globals[symbol.Index] = value
+}
+
+// Get returns a global, ok is false if it isn't defined
+func (rt *Runtime) Get(name string) (value data.Data, ok bool) {
+	symbol, ok := rt.symbols.Resolve(name)
+	if !ok || symbol.Scope != symbols.GlobalScope || symbol.Index >= len(rt.globals) || rt.globals[symbol.Index] == nil {
+		return nil, false
+	}
+	return rt.globals[symbol.Index], true
+}
+
+// Run compiles and runs a program, returning the value of its last expression
+func (rt *Runtime) Run(source string) (data.Data, error) {
+	return rt.RunContext(context.Background(), source)
+}
+
+// RunContext runs a program like Run, stopping once the context is done or the limits of the runtime are exceeded
+func (rt *Runtime) RunContext(ctx context.Context, source string) (data.Data, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &SyntaxError{Source: source, Errors: p.Errors()}
+	}
+
+	comp := compiler.NewWithState(rt.symbols, rt.constants)
+	comp.SetOptimization(rt.optimization)
+	err := comp.Compile(program)
+	if err != nil {
+		return nil, err
+	}
+
+	bytecode := comp.Bytecode()
+	rt.constants = bytecode.Constants
+
+	machine := rt.newMachine(bytecode)
+	defer rt.enter(machine)()
+
+	err = machine.RunContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return machine.Result(), nil
+}
+
+// Call calls an Ape function (ex. a closure returned by Get) with the given arguments (converted like ToData does)
+// Host functions may call it while a program runs, the call then counting against the limits of the run
+func (rt *Runtime) Call(fn data.Data, args ...interface{}) (data.Data, error) {
+	values, err := rt.arguments(args)
+	if err != nil {
+		return nil, err
+	}
+	if rt.machine != nil {
+		return rt.machine.Call(fn, values...)
+	}
+
+	machine := rt.newMachine(&compiler.Bytecode{Constants: rt.constants})
+	defer rt.enter(machine)()
+	return machine.CallContext(context.Background(), fn, values...)
+}
+
+// CallContext calls an Ape function like Call, stopping once the context is done or the limits of the runtime are exceeded
+func (rt *Runtime) CallContext(ctx context.Context, fn data.Data, args ...interface{}) (data.Data, error) {
+	values, err := rt.arguments(args)
+	if err != nil {
+		return nil, err
+	}
+
+	machine := rt.machine
+	if machine == nil {
+		machine = rt.newMachine(&compiler.Bytecode{Constants: rt.constants})
+		defer rt.enter(machine)()
+	}
+	return machine.CallContext(ctx, fn, values...)
+}
+
+func (rt *Runtime) arguments(args []interface{}) ([]data.Data, error) {
+	values := make([]data.Data, len(args))
+	for i, arg := range args {
+		value, err := ToData(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Creates a VM sharing the globals of the runtime
+// They are grown to hold every global defined so far, so the VM never grows (and detaches) them
+func (rt *Runtime) newMachine(bytecode *compiler.Bytecode) *vm.VM {
+	rt.growGlobals()
+	machine := vm.NewWithConfig(bytecode, rt.config)
+	machine.SetGlobals(rt.globals)
+	return machine
+}
+
+// Makes a VM the one running, returning a function restoring the previous one
+func (rt *Runtime) enter(machine *vm.VM) func() {
+	previous := rt.machine
+	rt.machine = machine
+	return func() {
+		rt.machine = previous
+		if previous != nil {
+			previous.SetGlobals(rt.globals)
+		}
+	}
+}
+
+func (rt *Runtime) growGlobals() {
+	if count := rt.symbols.DefinitionCount; count > len(rt.globals) {
+		globals := make([]data.Data, max(count, len(rt.globals)*2))
+		copy(globals, rt.globals)
+		rt.globals = globals
+
+		if rt.machine != nil {
+			rt.machine.SetGlobals(rt.globals)
+		}
+	}
+}
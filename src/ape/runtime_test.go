@@ -0,0 +1,239 @@
+package ape
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ape-lang/ape/src/compiler/vm"
+	"github.com/ape-lang/ape/src/data"
+)
+
+type user struct {
+	Name    string   `ape:"name"`
+	Age     int      `ape:"age"`
+	Tags    []string `ape:"tags"`
+	Secret  string   `ape:"-"`
+	Score   float64
+	private int
+}
+
+func TestHostFunctions(t *testing.T) {
+	rt := NewRuntime()
+
+	register := func(name string, fn interface{}) {
+		err := rt.Register(name, fn)
+		if err != nil {
+			t.Fatalf("could not register %s: %s", name, err)
+		}
+	}
+
+	register("add", func(a, b int) int { return a + b })
+	register("greet", func(u user) string { return u.Name + " is " + strings.Repeat("!", u.Age) })
+	register("user", func(name string) user { return user{Name: name, Age: 3, Tags: []string{"a"}, Secret: "s"} })
+	register("join", func(sep string, parts ...string) string { return strings.Join(parts, sep) })
+	register("half", func(n int) (float64, error) {
+		if n%2 != 0 {
+			return 0, errors.New("odd number")
+		}
+		return float64(n) / 2, nil
+	})
+	register("raw", func(args ...data.Data) data.Data { return &data.Integer{Value: int64(len(args))} })
+	register("apply", func(fn data.Data, x interface{}) (data.Data, error) { return rt.Call(fn, x) })
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"add(1, 2)", int64(3)},
+		{`greet({"name": "ape", "age": 2})`, "ape is !!"},
+		{`let u = user("ape"); [u["name"], u["age"], u["tags"], u["Score"], u["Secret"], u["private"]]`,
+			[]interface{}{"ape", int64(3), []interface{}{"a"}, 0.0, nil, nil}},
+		{`join("-", "a", "b", "c")`, "a-b-c"},
+		{`join("-")`, ""},
+		{"half(4)", 2.0},
+		{`try { half(3) } catch (e) { e.kind + ": " + e.message }`, "Error: odd number"},
+		{`try { add(1, "2") } catch (e) { e.message }`, "argument 2: cannot convert STRING to int"},
+		{`try { add(1) } catch (e) { e.message }`, "wrong number of arguments: want=2, got=1"},
+		{"raw(1, 2, 3)", int64(3)},
+		{"apply(fn(x) { x * 10 }, 4)", int64(40)},
+		{"apply(fn(x) { apply(fn(y) { x + y }, 1) }, 4)", int64(5)},
+		{`apply(len, "abc")`, int64(3)},
+		{`try { apply(fn(x) { throw("inner") }, 1) } catch (e) { e.message }`, "inner"},
+	}
+
+	for _, tt := range tests {
+		result, err := rt.Run(tt.input)
+		if err != nil {
+			t.Errorf("%q failed: %s", tt.input, err)
+			continue
+		}
+
+		var value interface{}
+		err = FromData(result, &value)
+		if err != nil {
+			t.Errorf("%q: could not convert the result: %s", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, tt.expected) {
+			t.Errorf("%q: want=%#v, got=%#v", tt.input, tt.expected, value)
+		}
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	rt := NewRuntime()
+
+	err := rt.Set("config", map[string]interface{}{"retries": 3, "hosts": []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("could not set config: %s", err)
+	}
+
+	_, err = rt.Run(`let count = config["retries"] * len(config["hosts"]); let double = fn(x) { x * 2 };`)
+	if err != nil {
+		t.Fatalf("run failed: %s", err)
+	}
+
+	count, ok := rt.Get("count")
+	if !ok {
+		t.Fatalf("count is not defined")
+	}
+	var n int
+	if err := FromData(count, &n); err != nil || n != 6 {
+		t.Errorf("wrong count: %d (%v)", n, err)
+	}
+
+	if _, ok := rt.Get("missing"); ok {
+		t.Errorf("expected missing to be undefined")
+	}
+
+	double, _ := rt.Get("double")
+	result, err := rt.Call(double, 21)
+	if err != nil {
+		t.Fatalf("call failed: %s", err)
+	}
+	if err := FromData(result, &n); err != nil || n != 42 {
+		t.Errorf("wrong result: %d (%v)", n, err)
+	}
+
+	_, err = rt.Call(double)
+	if err == nil || err.Error() != "wrong number of arguments: want=1, got=0" {
+		t.Errorf("expected a wrong number of arguments error, got %v", err)
+	}
+
+	// Globals set between runs are visible to the next ones, as are the previous globals
+	if err := rt.Set("count", 10); err != nil {
+		t.Fatalf("could not set count: %s", err)
+	}
+	result, err = rt.Run("double(count)")
+	if err != nil {
+		t.Fatalf("run failed: %s", err)
+	}
+	if err := FromData(result, &n); err != nil || n != 20 {
+		t.Errorf("wrong result: %d (%v)", n, err)
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	rt := NewRuntimeWithConfig(vm.Config{InitialStack: 64, MaxStack: 1024, MaxFrames: 64, Limits: data.Limits{Steps: 1000}})
+
+	_, err := rt.Run("let x = ;")
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("expected a *SyntaxError, got %T (%v)", err, err)
+	}
+
+	_, err = rt.Run(`throw("boom")`)
+	if runtimeErr, ok := err.(*vm.RuntimeError); !ok || runtimeErr.Message != "boom" {
+		t.Errorf("expected a *vm.RuntimeError, got %T (%v)", err, err)
+	}
+
+	_, err = rt.Run("while (true) {}")
+	if _, ok := err.(*data.StepLimitError); !ok {
+		t.Errorf("expected a *data.StepLimitError, got %T (%v)", err, err)
+	}
+
+	_, err = rt.Run("let loop = fn() { while (true) {} };")
+	if err != nil {
+		t.Fatalf("run failed: %s", err)
+	}
+	loop, _ := rt.Get("loop")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = rt.CallContext(ctx, loop)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled error, got %T (%v)", err, err)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		target   interface{} // a pointer to the zero value the result is converted back into
+		expected interface{}
+	}{
+		{42, new(int), 42},
+		{uint8(7), new(int64), int64(7)},
+		{1.5, new(float32), float32(1.5)},
+		{3, new(float64), 3.0},
+		{"ape", new(string), "ape"},
+		{true, new(bool), true},
+		{[]int{1, 2}, new([]int), []int{1, 2}},
+		{[2]string{"a", "b"}, new([2]string), [2]string{"a", "b"}},
+		{map[string]int{"a": 1}, new(map[string]int), map[string]int{"a": 1}},
+		{map[int]bool{1: true}, new(map[int]bool), map[int]bool{1: true}},
+		{user{Name: "ape", Age: 1, Secret: "s"}, new(user), user{Name: "ape", Age: 1}},
+		{&user{Name: "ape"}, new(*user), &user{Name: "ape"}},
+		{(*user)(nil), new(*user), (*user)(nil)},
+		{nil, new(interface{}), nil},
+		{[]interface{}{1, "a", nil}, new(interface{}), []interface{}{int64(1), "a", nil}},
+		{map[int]string{1: "a"}, new(interface{}), map[interface{}]interface{}{int64(1): "a"}},
+	}
+
+	for _, tt := range tests {
+		d, err := ToData(tt.value)
+		if err != nil {
+			t.Errorf("could not convert %#v: %s", tt.value, err)
+			continue
+		}
+
+		err = FromData(d, tt.target)
+		if err != nil {
+			t.Errorf("could not convert %s back: %s", d.Inspect(), err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.target).Elem().Interface(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("wrong conversion of %#v: want=%#v, got=%#v", tt.value, tt.expected, got)
+		}
+	}
+
+	errorTests := []struct {
+		d        data.Data
+		target   interface{}
+		expected string
+	}{
+		{&data.Integer{Value: 300}, new(int8), "300 overflows int8"},
+		{&data.Integer{Value: -1}, new(uint), "-1 overflows uint"},
+		{&data.String{Value: "a"}, new(int), "cannot convert STRING to int"},
+		{data.NewArray([]data.Data{data.TRUE}), new([2]bool), "cannot convert an array of 1 elements to [2]bool"},
+		{data.TRUE, 5, "the target must be a non-nil pointer, got int"},
+	}
+
+	for _, tt := range errorTests {
+		err := FromData(tt.d, tt.target)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error converting %s: want=%q, got=%v", tt.d.Inspect(), tt.expected, err)
+		}
+	}
+
+	if _, err := ToData(make(chan int)); err == nil {
+		t.Errorf("expected channels to be unsupported")
+	}
+	if d, err := ToData(map[bool][]int{true: nil}); err != nil || d.Inspect() != "{true: null}" {
+		t.Errorf("wrong conversion of a map of nil slices: %v (%v)", d, err)
+	}
+	if _, err := ToData(map[[1]int]int{{1}: 1}); err == nil || err.Error() != "unusable as hash key: ARRAY" {
+		t.Errorf("expected an unusable key error, got %v", err)
+	}
+}
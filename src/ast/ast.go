@@ -1,7 +1,10 @@
 package ast
 
+import "github.com/ape-lang/ape/src/token"
+
 type Node interface {
 	TokenLiteral() string
+	Position() token.Position
 	String() string
 }
 
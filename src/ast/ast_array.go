@@ -15,6 +15,8 @@ func (al *ArrayLiteral) expressionNode() {}
 
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
 
+func (al *ArrayLiteral) Position() token.Position { return al.Token.Position }
+
 func (al *ArrayLiteral) String() string {
 	var sb strings.Builder
 	elements := []string{}
@@ -16,6 +16,8 @@ func (ie *IndexExpression) expressionNode() {}
 
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
 
+func (ie *IndexExpression) Position() token.Position { return ie.Token.Position }
+
 func (ie *IndexExpression) String() string {
 	var sb strings.Builder
 
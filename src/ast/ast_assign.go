@@ -0,0 +1,38 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+// AssignExpression updates a variable or an index (the operator is either '=' or a compound one like '+=')
+type AssignExpression struct {
+	Token    token.Token
+	Target   Expression // An *Identifier or an *IndexExpression
+	Operator string
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+
+func (ae *AssignExpression) Position() token.Position { return ae.Token.Position }
+
+func (ae *AssignExpression) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("(")
+	sb.WriteString(ae.Target.String())
+	sb.WriteString(" " + ae.Operator + " ")
+	sb.WriteString(ae.Value.String())
+	sb.WriteString(")")
+
+	return sb.String()
+}
+
+// BinaryOperator returns the operator combining the target and the value (empty for plain assignments)
+func (ae *AssignExpression) BinaryOperator() string {
+	return strings.TrimSuffix(ae.Operator, "=")
+}
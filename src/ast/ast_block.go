@@ -15,6 +15,8 @@ func (bs *BlockStatement) statementNode() {}
 
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
 
+func (bs *BlockStatement) Position() token.Position { return bs.Token.Position }
+
 func (bs *BlockStatement) String() string {
 	var sb strings.Builder
 
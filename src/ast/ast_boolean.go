@@ -7,6 +7,7 @@ type Boolean struct {
 	Value bool
 }
 
-func (b *Boolean) expressionNode()      {}
-func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
-func (b *Boolean) String() string       { return b.Token.Literal }
+func (b *Boolean) expressionNode()          {}
+func (b *Boolean) TokenLiteral() string     { return b.Token.Literal }
+func (b *Boolean) Position() token.Position { return b.Token.Position }
+func (b *Boolean) String() string           { return b.Token.Literal }
@@ -0,0 +1,15 @@
+package ast
+
+import "github.com/ape-lang/ape/src/token"
+
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+
+func (bs *BreakStatement) Position() token.Position { return bs.Token.Position }
+
+func (bs *BreakStatement) String() string { return bs.TokenLiteral() + ";" }
@@ -16,6 +16,8 @@ func (ce *CallExpression) expressionNode() {}
 
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
 
+func (ce *CallExpression) Position() token.Position { return ce.Token.Position }
+
 func (ce *CallExpression) String() string {
 	var sb strings.Builder
 	args := []string{}
@@ -0,0 +1,15 @@
+package ast
+
+import "github.com/ape-lang/ape/src/token"
+
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+
+func (cs *ContinueStatement) Position() token.Position { return cs.Token.Position }
+
+func (cs *ContinueStatement) String() string { return cs.TokenLiteral() + ";" }
@@ -0,0 +1,16 @@
+package ast
+
+import "github.com/ape-lang/ape/src/token"
+
+type ExportStatement struct {
+	Token token.Token
+	Let   *LetStatement
+}
+
+func (es *ExportStatement) statementNode() {}
+
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+
+func (es *ExportStatement) Position() token.Position { return es.Token.Position }
+
+func (es *ExportStatement) String() string { return "export " + es.Let.String() }
@@ -17,6 +17,8 @@ func (oe *InfixExpression) expressionNode() {}
 
 func (oe *InfixExpression) TokenLiteral() string { return oe.Token.Literal }
 
+func (oe *InfixExpression) Position() token.Position { return oe.Token.Position }
+
 func (oe *InfixExpression) String() string {
 	var sb strings.Builder
 
@@ -16,6 +16,8 @@ func (pe *PrefixExpression) expressionNode() {}
 
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
 
+func (pe *PrefixExpression) Position() token.Position { return pe.Token.Position }
+
 func (pe *PrefixExpression) String() string {
 	var sb strings.Builder
 
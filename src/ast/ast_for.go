@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+type ForStatement struct {
+	Token    token.Token
+	Name     *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+
+func (fs *ForStatement) Position() token.Position { return fs.Token.Position }
+
+func (fs *ForStatement) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("for(")
+	sb.WriteString(fs.Name.String())
+	sb.WriteString(" in ")
+	sb.WriteString(fs.Iterable.String())
+	sb.WriteString(") ")
+	sb.WriteString(fs.Body.String())
+
+	return sb.String()
+}
@@ -10,12 +10,15 @@ type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Name       string // The name the function is bound to by a let statement (if any)
 }
 
 func (fl *FunctionLiteral) expressionNode() {}
 
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 
+func (fl *FunctionLiteral) Position() token.Position { return fl.Token.Position }
+
 func (fl *FunctionLiteral) String() string {
 	var sb strings.Builder
 	params := []string{}
@@ -8,19 +8,26 @@ import (
 
 type HashLiteral struct {
 	Token token.Token
-	Pairs map[Expression]Expression
+	Pairs []HashLiteralPair // In source order
+}
+
+type HashLiteralPair struct {
+	Key   Expression
+	Value Expression
 }
 
 func (hl *HashLiteral) expressionNode() {}
 
 func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 
+func (hl *HashLiteral) Position() token.Position { return hl.Token.Position }
+
 func (hl *HashLiteral) String() string {
 	var sb strings.Builder
 	pairs := []string{}
 
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
 	}
 
 	sb.WriteString("{")
@@ -7,6 +7,7 @@ type Identifier struct {
 	Value string
 }
 
-func (i *Identifier) expressionNode()      {}
-func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
-func (i *Identifier) String() string       { return i.Value }
+func (i *Identifier) expressionNode()          {}
+func (i *Identifier) TokenLiteral() string     { return i.Token.Literal }
+func (i *Identifier) Position() token.Position { return i.Token.Position }
+func (i *Identifier) String() string           { return i.Value }
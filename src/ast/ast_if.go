@@ -17,6 +17,8 @@ func (ie *IfExpression) expressionNode() {}
 
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
 
+func (ie *IfExpression) Position() token.Position { return ie.Token.Position }
+
 func (ie *IfExpression) String() string {
 	var sb strings.Builder
 
@@ -0,0 +1,36 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+type ImportStatement struct {
+	Token token.Token
+	Path  string
+	Name  *Identifier   // The name the module is bound to (nil when importing exports by name)
+	Names []*Identifier // The imported exports (nil when importing the whole module)
+}
+
+func (is *ImportStatement) statementNode() {}
+
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+
+func (is *ImportStatement) Position() token.Position { return is.Token.Position }
+
+func (is *ImportStatement) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("import ")
+	if is.Name == nil {
+		names := []string{}
+		for _, n := range is.Names {
+			names = append(names, n.String())
+		}
+		sb.WriteString("{ " + strings.Join(names, ", ") + " } from ")
+	}
+	sb.WriteString("\"" + is.Path + "\";")
+
+	return sb.String()
+}
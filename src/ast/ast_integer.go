@@ -7,6 +7,7 @@ type IntegerLiteral struct {
 	Value int64
 }
 
-func (il *IntegerLiteral) expressionNode()      {}
-func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
-func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+func (il *IntegerLiteral) expressionNode()          {}
+func (il *IntegerLiteral) TokenLiteral() string     { return il.Token.Literal }
+func (il *IntegerLiteral) Position() token.Position { return il.Token.Position }
+func (il *IntegerLiteral) String() string           { return il.Token.Literal }
@@ -10,12 +10,15 @@ type LetStatement struct {
 	Token token.Token
 	Name  *Identifier
 	Value Expression
+	Doc   string // The doc comment right before the statement
 }
 
 func (ls *LetStatement) statementNode() {}
 
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
 
+func (ls *LetStatement) Position() token.Position { return ls.Token.Position }
+
 func (ls *LetStatement) String() string {
 	var sb strings.Builder
 
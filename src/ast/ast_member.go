@@ -0,0 +1,32 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+// MemberExpression accesses a named member of a value, ex. the export of a module (`module.name`)
+type MemberExpression struct {
+	Token  token.Token
+	Object Expression
+	Member *Identifier
+}
+
+func (me *MemberExpression) expressionNode() {}
+
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+
+func (me *MemberExpression) Position() token.Position { return me.Token.Position }
+
+func (me *MemberExpression) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("(")
+	sb.WriteString(me.Object.String())
+	sb.WriteString(".")
+	sb.WriteString(me.Member.String())
+	sb.WriteString(")")
+
+	return sb.String()
+}
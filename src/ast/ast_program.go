@@ -1,6 +1,10 @@
 package ast
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
 
 type Program struct {
 	Statements []Statement
@@ -14,6 +18,14 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Position() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Position()
+	}
+
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var sb strings.Builder
 
@@ -15,6 +15,8 @@ func (rs *ReturnStatement) statementNode() {}
 
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
 
+func (rs *ReturnStatement) Position() token.Position { return rs.Token.Position }
+
 func (rs *ReturnStatement) String() string {
 	var sb strings.Builder
 
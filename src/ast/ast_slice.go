@@ -0,0 +1,39 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+// SliceExpression takes a part of a string or an array (`left[start:end]`), Start and End being nil when omitted
+type SliceExpression struct {
+	Token token.Token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (se *SliceExpression) expressionNode() {}
+
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+
+func (se *SliceExpression) Position() token.Position { return se.Token.Position }
+
+func (se *SliceExpression) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("(")
+	sb.WriteString(se.Left.String())
+	sb.WriteString("[")
+	if se.Start != nil {
+		sb.WriteString(se.Start.String())
+	}
+	sb.WriteString(":")
+	if se.End != nil {
+		sb.WriteString(se.End.String())
+	}
+	sb.WriteString("])")
+
+	return sb.String()
+}
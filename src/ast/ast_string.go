@@ -7,6 +7,7 @@ type StringLiteral struct {
 	Value string
 }
 
-func (sl *StringLiteral) expressionNode()      {}
-func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
-func (sl *StringLiteral) String() string       { return sl.Token.Literal }
+func (sl *StringLiteral) expressionNode()          {}
+func (sl *StringLiteral) TokenLiteral() string     { return sl.Token.Literal }
+func (sl *StringLiteral) Position() token.Position { return sl.Token.Position }
+func (sl *StringLiteral) String() string           { return sl.Token.Literal }
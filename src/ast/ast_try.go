@@ -0,0 +1,40 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+type TryStatement struct {
+	Token   token.Token
+	Body    *BlockStatement
+	Name    *Identifier     // The name the caught exception is bound to (nil without a catch)
+	Catch   *BlockStatement // nil without a catch
+	Finally *BlockStatement // nil without a finally
+}
+
+func (ts *TryStatement) statementNode() {}
+
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Literal }
+
+func (ts *TryStatement) Position() token.Position { return ts.Token.Position }
+
+func (ts *TryStatement) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("try ")
+	sb.WriteString(ts.Body.String())
+	if ts.Catch != nil {
+		sb.WriteString(" catch(")
+		sb.WriteString(ts.Name.String())
+		sb.WriteString(") ")
+		sb.WriteString(ts.Catch.String())
+	}
+	if ts.Finally != nil {
+		sb.WriteString(" finally ")
+		sb.WriteString(ts.Finally.String())
+	}
+
+	return sb.String()
+}
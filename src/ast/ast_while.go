@@ -0,0 +1,30 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+type WhileStatement struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+
+func (ws *WhileStatement) Position() token.Position { return ws.Token.Position }
+
+func (ws *WhileStatement) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("while")
+	sb.WriteString(ws.Condition.String())
+	sb.WriteString(" ")
+	sb.WriteString(ws.Body.String())
+
+	return sb.String()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ape-lang/ape/src/compiler/compiler"
+)
+
+// build compiles a script file into a compiled file, which `run` executes without compiling it again
+func build(path string, output string, optimization int) int {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read file:\n Error: %s\n", err)
+		return 1
+	}
+
+	bytecode, ok := compile(path, string(source), optimization)
+	if !ok {
+		return 1
+	}
+
+	encoded, err := bytecode.MarshalBinary()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not encode bytecode:\n Error: %s\n", err)
+		return 1
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(path, filepath.Ext(path)) + compiler.FileExtension
+	}
+	err = os.WriteFile(output, encoded, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write file:\n Error: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,262 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ape-lang/ape/src/compiler/operation"
+	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/token"
+)
+
+// Magic is the signature at the start of compiled (.apec) files
+const Magic = "APEC"
+
+// FormatVersion is the version of the compiled file format, changed whenever the format or the opcodes change
+const FormatVersion = 4
+
+// FileExtension is the extension of compiled files
+const FileExtension = ".apec"
+
+// ErrTruncated is returned when decoding compiled files which end unexpectedly
+var ErrTruncated = errors.New("truncated bytecode")
+
+// The tags of the constant types in compiled files
+const (
+	integerTag byte = iota + 1
+	floatTag
+	stringTag
+	functionTag
+)
+
+// MarshalBinary encodes the bytecode in the compiled file format:
+//
+//	magic, version (uint16), instructions, positions, constants
+//
+// Instructions are length-prefixed, positions are the debug info mapping them to the source
+// and each constant starts with a tag byte giving its type (functions nest their own instructions and positions)
+func (b *Bytecode) MarshalBinary() ([]byte, error) {
+	e := &encoder{files: make(map[string]int)}
+	e.buf.WriteString(Magic)
+	e.buf.Write(binary.BigEndian.AppendUint16(nil, FormatVersion))
+
+	e.bytes(b.Instructions)
+	e.positions(b.Positions)
+
+	e.uint(len(b.Constants))
+	for _, c := range b.Constants {
+		err := e.constant(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes bytecode in the compiled file format, rejecting files of other versions and instructions the
+// VM can't execute (ex. unknown opcodes, or constants and jumps out of range)
+func (b *Bytecode) UnmarshalBinary(input []byte) error {
+	if len(input) < len(Magic)+2 || string(input[:len(Magic)]) != Magic {
+		return fmt.Errorf("not a compiled ape file (missing %q signature)", Magic)
+	}
+	version := binary.BigEndian.Uint16(input[len(Magic):])
+	if version != FormatVersion {
+		return fmt.Errorf("unsupported bytecode version %d (expected %d)", version, FormatVersion)
+	}
+
+	d := &decoder{input: input[len(Magic)+2:]}
+	instructions := d.bytes()
+	positions := d.positions()
+
+	count := d.uint()
+	constants := []data.Data{}
+	for i := 0; i < count && d.err == nil; i++ {
+		constants = append(constants, d.constant())
+	}
+
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.input) != 0 {
+		return fmt.Errorf("unexpected %d bytes after the bytecode", len(d.input))
+	}
+
+	decoded := &Bytecode{Instructions: instructions, Positions: positions, Constants: constants, decoded: true}
+	if err := decoded.validate(); err != nil {
+		return err
+	}
+	*b = *decoded
+	return nil
+}
+
+type encoder struct {
+	buf   bytes.Buffer
+	files map[string]int // The indexes of the file names already written
+}
+
+func (e *encoder) uint(n int) {
+	e.buf.Write(binary.AppendUvarint(nil, uint64(n)))
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.uint(len(b))
+	e.buf.Write(b)
+}
+
+func (e *encoder) string(s string) {
+	e.bytes([]byte(s))
+}
+
+// File names are written once, later positions reference them by index
+func (e *encoder) file(name string) {
+	if index, ok := e.files[name]; ok {
+		e.uint(index)
+		return
+	}
+	e.files[name] = len(e.files)
+	e.uint(len(e.files) - 1)
+	e.string(name)
+}
+
+func (e *encoder) positions(positions []data.SourcePosition) {
+	e.uint(len(positions))
+	for _, p := range positions {
+		e.uint(p.Offset)
+		e.file(p.Position.File)
+		e.uint(p.Position.Line)
+		e.uint(p.Position.Column)
+	}
+}
+
+func (e *encoder) constant(c data.Data) error {
+	switch c := c.(type) {
+	case *data.Integer:
+		e.buf.WriteByte(integerTag)
+		e.buf.Write(binary.AppendVarint(nil, c.Value))
+	case *data.Float:
+		e.buf.WriteByte(floatTag)
+		e.buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(c.Value)))
+	case *data.String:
+		e.buf.WriteByte(stringTag)
+		e.string(c.Value)
+	case *data.CompiledFunction:
+		e.buf.WriteByte(functionTag)
+		e.string(c.Name)
+		e.uint(c.LocalCount)
+		e.uint(c.ParamCount)
+		e.bytes(c.Instructions)
+		e.positions(c.Positions)
+	default:
+		return fmt.Errorf("unsupported constant type %s", c.Type())
+	}
+	return nil
+}
+
+type decoder struct {
+	input []byte
+	files []string
+	err   error // The first error, after which nothing is decoded
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.input = nil
+}
+
+func (d *decoder) uint() int {
+	n, size := binary.Uvarint(d.input)
+	if size <= 0 || n > math.MaxInt32 {
+		d.fail(ErrTruncated)
+		return 0
+	}
+	d.input = d.input[size:]
+	return int(n)
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uint()
+	if n > len(d.input) {
+		d.fail(ErrTruncated)
+		return nil
+	}
+	b := make([]byte, n)
+	copy(b, d.input)
+	d.input = d.input[n:]
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+func (d *decoder) file() string {
+	index := d.uint()
+	switch {
+	case index < len(d.files):
+		return d.files[index]
+	case index == len(d.files):
+		d.files = append(d.files, d.string())
+		return d.files[index]
+	default:
+		d.fail(fmt.Errorf("invalid file reference %d", index))
+		return ""
+	}
+}
+
+func (d *decoder) positions() []data.SourcePosition {
+	count := d.uint()
+	var positions []data.SourcePosition
+	for i := 0; i < count && d.err == nil; i++ {
+		offset := d.uint()
+		pos := token.Position{File: d.file(), Line: d.uint(), Column: d.uint()}
+		positions = append(positions, data.SourcePosition{Offset: offset, Position: pos})
+	}
+	return positions
+}
+
+func (d *decoder) constant() data.Data {
+	if len(d.input) == 0 {
+		d.fail(ErrTruncated)
+		return nil
+	}
+	tag := d.input[0]
+	d.input = d.input[1:]
+
+	switch tag {
+	case integerTag:
+		n, size := binary.Varint(d.input)
+		if size <= 0 {
+			d.fail(ErrTruncated)
+			return nil
+		}
+		d.input = d.input[size:]
+		return &data.Integer{Value: n}
+	case floatTag:
+		if len(d.input) < 8 {
+			d.fail(ErrTruncated)
+			return nil
+		}
+		bits := binary.BigEndian.Uint64(d.input)
+		d.input = d.input[8:]
+		return &data.Float{Value: math.Float64frombits(bits)}
+	case stringTag:
+		return &data.String{Value: d.string()}
+	case functionTag:
+		return &data.CompiledFunction{
+			Name:         d.string(),
+			LocalCount:   d.uint(),
+			ParamCount:   d.uint(),
+			Instructions: operation.Instruction(d.bytes()),
+			Positions:    d.positions(),
+		}
+	default:
+		d.fail(fmt.Errorf("unknown constant tag %d", tag))
+		return nil
+	}
+}
@@ -2,18 +2,29 @@ package compiler
 
 import (
 	"fmt"
-	"sort"
+	"maps"
+	"math"
 
 	"github.com/ape-lang/ape/src/ast"
 	"github.com/ape-lang/ape/src/compiler/operation"
 	"github.com/ape-lang/ape/src/compiler/symbols"
 	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/module"
+	"github.com/ape-lang/ape/src/token"
 )
 
 // Bytecode contains the instructions and constants the compiler generated and evaluated
 type Bytecode struct {
 	Instructions operation.Instruction
 	Constants    []data.Data
+	Positions    []data.SourcePosition // The source positions of the instructions
+
+	decoded bool // Read from a compiled file rather than generated
+}
+
+// Decoded checks whether the bytecode was read from a compiled file (by UnmarshalBinary) rather than generated
+func (b *Bytecode) Decoded() bool {
+	return b.decoded
 }
 
 // Emitted represents an emitted instruction
@@ -28,6 +39,18 @@ type Compiler struct {
 	symbols      *symbols.SymbolTable
 	scopes       []Scope
 	currentScope int
+	position     token.Position       // The position of the node being compiled
+	globals      *symbols.SymbolTable // The symbol table of the program, also holding the imported modules
+	loader       *module.Loader       // Finds the imported modules
+	optimization int                  // The optimization level (O0 or O1)
+	base         int                  // The constants before this index were added by a previous compiler (ex. in the REPL)
+	constantKeys map[constantKey]int  // The indexes of the deduplicated constants
+}
+
+// Identifies a constant which can be deduplicated
+type constantKey struct {
+	dataType data.DataType
+	value    uint64
 }
 
 // Scope contains the scope of the compilation
@@ -35,6 +58,22 @@ type Scope struct {
 	instructions operation.Instruction // The instructions that will be compiled
 	emitted      Emitted               // The last emitted instruction
 	prevEmitted  Emitted               // The emitted instruction before that
+	loops        []*Loop               // The loops enclosing the current instruction
+	tries        []*Try                // The exception handlers active at the current instruction
+	positions    []data.SourcePosition // The source positions of the instructions
+}
+
+// Loop contains the jump targets of a loop being compiled
+type Loop struct {
+	start    int   // The position `continue` jumps to
+	breaks   []int // The positions of the `break` jumps, changed once the end of the loop is known
+	iterator bool  // Whether the loop keeps an iterator on the stack
+	tries    int   // The number of exception handlers active outside of the loop
+}
+
+// Try contains an exception handler being compiled
+type Try struct {
+	finally *ast.BlockStatement // Run whenever the handler is left (nil without a finally)
 }
 
 // New creates a new compiler
@@ -45,16 +84,15 @@ func New() *Compiler {
 		prevEmitted:  Emitted{},
 	}
 
-	symbolTable := symbols.New()
-	for i, v := range data.Builtins {
-		symbolTable.DefineBuiltin(i, v.Name)
-	}
+	symbolTable := newSymbolTable()
 
 	return &Compiler{
 		constants:    []data.Data{},
 		symbols:      symbolTable,
 		scopes:       []Scope{rootScope},
 		currentScope: 0,
+		globals:      symbolTable,
+		loader:       module.NewLoader(module.DefaultPath()),
 	}
 }
 
@@ -62,12 +100,39 @@ func New() *Compiler {
 func NewWithState(syms *symbols.SymbolTable, consts []data.Data) *Compiler {
 	c := New()
 	c.constants = consts
+	c.base = len(consts)
 	c.symbols = syms
+	c.globals = syms
 	return c
 }
 
+// SetLoader sets the loader used to find the imported modules
+func (c *Compiler) SetLoader(loader *module.Loader) {
+	c.loader = loader
+}
+
+// SetOptimization sets the optimization level of the compiled instructions (O0 by default)
+func (c *Compiler) SetOptimization(level int) {
+	c.optimization = level
+}
+
+// Creates a symbol table holding the builtins
+func newSymbolTable() *symbols.SymbolTable {
+	symbolTable := symbols.New()
+	for i, v := range data.Builtins {
+		symbolTable.DefineBuiltin(i, v.Name)
+	}
+	return symbolTable
+}
+
 // Compile compiles an AST and populates the instructions and constants accordingly
 func (c *Compiler) Compile(node ast.Node) error {
+	// The instructions emitted for a node are mapped to its position (unless a child node emits them)
+	if pos := node.Position(); pos.IsValid() {
+		defer c.setPosition(c.position)
+		c.setPosition(pos)
+	}
+
 	switch node := node.(type) {
 	case *ast.Program:
 		for _, s := range node.Statements {
@@ -85,22 +150,9 @@ func (c *Compiler) Compile(node ast.Node) error {
 		c.emit(operation.Pop)
 
 	case *ast.InfixExpression:
-		// Convert LessThan operations to GreaterThan ones
-		if node.Operator == "<" {
-
-			// First compile the right node, then the left node, unlike other infixes
-			err := c.Compile(node.Right)
-			if err != nil {
-				return err
-			}
-
-			err = c.Compile(node.Left)
-			if err != nil {
-				return err
-			}
-
-			c.emit(operation.GreaterThan)
-			return nil
+		// Logical operators short-circuit, so the right node is compiled behind a jump
+		if node.Operator == "&&" || node.Operator == "||" {
+			return c.compileLogical(node)
 		}
 
 		err := c.Compile(node.Left)
@@ -122,14 +174,32 @@ func (c *Compiler) Compile(node ast.Node) error {
 			c.emit(operation.Mul)
 		case "/":
 			c.emit(operation.Div)
+		case "%":
+			c.emit(operation.Mod)
+		case "&":
+			c.emit(operation.BitAnd)
+		case "|":
+			c.emit(operation.BitOr)
+		case "^":
+			c.emit(operation.BitXor)
+		case "<<":
+			c.emit(operation.ShiftLeft)
+		case ">>":
+			c.emit(operation.ShiftRight)
 		case ">":
 			c.emit(operation.GreaterThan)
+		case ">=":
+			c.emit(operation.GreaterThanOrEqual)
+		case "<":
+			c.emit(operation.LessThan)
+		case "<=":
+			c.emit(operation.LessThanOrEqual)
 		case "==":
 			c.emit(operation.Equal)
 		case "!=":
 			c.emit(operation.NotEqual)
 		default:
-			return fmt.Errorf("unknown operator %s", node.Operator)
+			return fmt.Errorf("%s: unknown operator %s", node.Position(), node.Operator)
 		}
 
 	case *ast.PrefixExpression:
@@ -143,14 +213,20 @@ func (c *Compiler) Compile(node ast.Node) error {
 			c.emit(operation.Bang)
 		case "-":
 			c.emit(operation.Minus)
+		case "~":
+			c.emit(operation.BitNot)
 		default:
-			return fmt.Errorf("unknown operator %s", node.Operator)
+			return fmt.Errorf("%s: unknown operator %s", node.Position(), node.Operator)
 		}
 
 	case *ast.IntegerLiteral:
 		integer := &data.Integer{Value: node.Value}
 		c.emit(operation.Constant, c.addConstant(integer))
 
+	case *ast.FloatLiteral:
+		float := &data.Float{Value: node.Value}
+		c.emit(operation.Constant, c.addConstant(float))
+
 	case *ast.Boolean:
 		if node.Value {
 			c.emit(operation.True)
@@ -172,20 +248,12 @@ func (c *Compiler) Compile(node ast.Node) error {
 		c.emit(operation.Array, len(node.Elements))
 
 	case *ast.HashLiteral:
-		keys := []ast.Expression{}
-		for k := range node.Pairs {
-			keys = append(keys, k)
-		}
-		// Sort the keys as Go doesn't guarantee key/val order on iteration
-		sort.Slice(keys, func(i, j int) bool {
-			return keys[i].String() < keys[j].String()
-		})
-		for _, k := range keys {
-			err := c.Compile(k)
+		for _, pair := range node.Pairs {
+			err := c.Compile(pair.Key)
 			if err != nil {
 				return err
 			}
-			err = c.Compile(node.Pairs[k])
+			err = c.Compile(pair.Value)
 			if err != nil {
 				return err
 			}
@@ -203,6 +271,32 @@ func (c *Compiler) Compile(node ast.Node) error {
 		}
 		c.emit(operation.Index)
 
+	case *ast.SliceExpression:
+		err := c.Compile(node.Left)
+		if err != nil {
+			return err
+		}
+		for _, bound := range []ast.Expression{node.Start, node.End} {
+			if bound == nil {
+				c.emit(operation.Null)
+				continue
+			}
+			err = c.Compile(bound)
+			if err != nil {
+				return err
+			}
+		}
+		c.emit(operation.Slice)
+
+	case *ast.MemberExpression:
+		err := c.Compile(node.Object)
+		if err != nil {
+			return err
+		}
+		member := &data.String{Value: node.Member.Value}
+		c.emit(operation.Constant, c.addConstant(member))
+		c.emit(operation.Index)
+
 	case *ast.IfExpression:
 		err := c.Compile(node.Condition)
 		if err != nil {
@@ -215,8 +309,11 @@ func (c *Compiler) Compile(node ast.Node) error {
 		if err != nil {
 			return err
 		}
-		if c.isEmitted(operation.Pop) {
+		if endsWithExpression(node.Consequent) {
 			c.preventPop()
+		} else {
+			// The block didn't end with an expression (ex. a loop), so it results in null
+			c.emit(operation.Null)
 		}
 
 		// Emit a `Jump` with a temporary operand
@@ -230,8 +327,10 @@ func (c *Compiler) Compile(node ast.Node) error {
 			if err != nil {
 				return err
 			}
-			if c.isEmitted(operation.Pop) {
+			if endsWithExpression(node.Alternate) {
 				c.preventPop()
+			} else {
+				c.emit(operation.Null)
 			}
 		}
 
@@ -245,16 +344,93 @@ func (c *Compiler) Compile(node ast.Node) error {
 			return err
 		}
 
-		if symbol.Scope == symbols.GlobalScope {
-			c.emit(operation.SetGlobal, symbol.Index)
-		} else {
-			c.emit(operation.SetLocal, symbol.Index)
+		c.setSymbol(symbol)
+
+	case *ast.AssignExpression:
+		return c.compileAssign(node)
+
+	case *ast.ImportStatement:
+		return c.compileImport(node)
+
+	case *ast.ExportStatement:
+		return c.Compile(node.Let)
+
+	case *ast.WhileStatement:
+		start := len(c.currentInstructions())
+		err := c.Compile(node.Condition)
+		if err != nil {
+			return err
+		}
+
+		// Emit a `JumpNotTruthy` with a temporary operand
+		jumpNotTruthyPos := c.emit(operation.JumpNotTruthy, 9999)
+		c.enterLoop(start, false)
+		err = c.Compile(node.Body)
+		if err != nil {
+			return err
 		}
+		c.emit(operation.Jump, start)
+
+		end := len(c.currentInstructions())
+		c.changeOperand(jumpNotTruthyPos, end)
+		c.leaveLoop(end)
+
+	case *ast.ForStatement:
+		err := c.Compile(node.Iterable)
+		if err != nil {
+			return err
+		}
+		c.emit(operation.Iterator)
+
+		// Emit an `IterNext` with a temporary operand, the iterator is popped once exhausted
+		start := c.emit(operation.IterNext, 9999)
+		c.setSymbol(c.symbols.Define(node.Name.Value))
+
+		c.enterLoop(start, true)
+		err = c.Compile(node.Body)
+		if err != nil {
+			return err
+		}
+		c.emit(operation.Jump, start)
+
+		end := len(c.currentInstructions())
+		c.changeOperand(start, end)
+		c.leaveLoop(end)
+
+	case *ast.TryStatement:
+		return c.compileTry(node)
+
+	case *ast.BreakStatement:
+		loop := c.currentLoop()
+		if loop == nil {
+			return fmt.Errorf("%s: break outside of a loop", node.Position())
+		}
+		err := c.exitTries(loop.tries)
+		if err != nil {
+			return err
+		}
+
+		// The iterator is left on the stack only when the loop ends by itself
+		if loop.iterator {
+			c.emit(operation.Pop)
+		}
+		loop.breaks = append(loop.breaks, c.emit(operation.Jump, 9999))
+
+	case *ast.ContinueStatement:
+		loop := c.currentLoop()
+		if loop == nil {
+			return fmt.Errorf("%s: continue outside of a loop", node.Position())
+		}
+		err := c.exitTries(loop.tries)
+		if err != nil {
+			return err
+		}
+		c.emit(operation.Jump, loop.start)
 
 	case *ast.Identifier:
 		symbol, ok := c.symbols.Resolve(node.Value)
 		if !ok {
-			return fmt.Errorf("Variable %s is undefined", node.Value)
+			return fmt.Errorf("%s: Variable %s is undefined", node.Position(), node.Value)
 		}
 		c.loadSymbol(symbol)
 
@@ -276,28 +452,36 @@ func (c *Compiler) Compile(node ast.Node) error {
 			return err
 		}
 
-		// If a pop was emitted, replace it with a return value (implicit return)
-		if c.isEmitted(operation.Pop) {
+		switch lastStatement(node.Body).(type) {
+		case *ast.ExpressionStatement:
+			// Replace the pop with a return value (implicit return)
 			c.changeEmittedTo(operation.ReturnValue)
-		}
-
-		// If no return value was emitted, emit a return instead (no return - empty body)
-		if !c.isEmitted(operation.ReturnValue) {
+		case *ast.ReturnStatement:
+		default:
+			// The body doesn't end with a return, so return nothing (ex. empty body)
 			c.emit(operation.Return)
 		}
 
 		freeSymbols := c.symbols.Free
 		localCount := c.symbols.DefinitionCount
+		positions := c.scopes[c.currentScope].positions
 		instructions := c.leaveScope()
+		if c.optimization >= O1 {
+			instructions, positions = c.optimize(instructions, positions)
+		}
+		markTailCalls(instructions)
 
+		// Captured variables are passed as cells, so assignments are shared with the enclosing function
 		for _, s := range freeSymbols {
-			c.loadSymbol(s)
+			c.loadCell(s)
 		}
 
 		compiled := &data.CompiledFunction{
 			Instructions: instructions,
 			LocalCount:   localCount,
 			ParamCount:   len(node.Parameters),
+			Name:         node.Name,
+			Positions:    positions,
 		}
 
 		fnIndex := c.addConstant(compiled)
@@ -308,6 +492,10 @@ func (c *Compiler) Compile(node ast.Node) error {
 		if err != nil {
 			return err
 		}
+		err = c.exitTries(0)
+		if err != nil {
+			return err
+		}
 		c.emit(operation.ReturnValue)
 
 	case *ast.CallExpression:
@@ -328,16 +516,353 @@ func (c *Compiler) Compile(node ast.Node) error {
 	return nil
 }
 
+// Compiles a logical operator, only evaluating the right node when the left one doesn't decide the result
+func (c *Compiler) compileLogical(node *ast.InfixExpression) error {
+	err := c.Compile(node.Left)
+	if err != nil {
+		return err
+	}
+
+	// Emit a `JumpNotTruthy` with a temporary operand
+	jumpNotTruthyPos := c.emit(operation.JumpNotTruthy, 9999)
+
+	if node.Operator == "&&" {
+		// A truthy left node hands the result over to the right node
+		err = c.Compile(node.Right)
+		if err != nil {
+			return err
+		}
+		c.emit(operation.Bang)
+		c.emit(operation.Bang)
+		jumpPos := c.emit(operation.Jump, 9999)
+
+		c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+		c.emit(operation.False)
+		c.changeOperand(jumpPos, len(c.currentInstructions()))
+		return nil
+	}
+
+	// A truthy left node decides the result of `||`
+	c.emit(operation.True)
+	jumpPos := c.emit(operation.Jump, 9999)
+
+	c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+	err = c.Compile(node.Right)
+	if err != nil {
+		return err
+	}
+	c.emit(operation.Bang)
+	c.emit(operation.Bang)
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+	return nil
+}
+
+// Compiles a try statement, the exception being caught is pushed on the stack by the handler
+// Without a catch (or when the catch raises) the finally block runs before raising the exception again
+func (c *Compiler) compileTry(node *ast.TryStatement) error {
+	// Emit a `SetupTry` with a temporary operand
+	setupTryPos := c.emit(operation.SetupTry, 9999)
+	c.enterTry(node.Finally)
+	err := c.Compile(node.Body)
+	if err != nil {
+		return err
+	}
+	c.leaveTry()
+	c.emit(operation.PopTry)
+
+	if node.Catch != nil {
+		// Emit a `Jump` with a temporary operand, skipping the catch block
+		jumpPos := c.emit(operation.Jump, 9999)
+		c.changeOperand(setupTryPos, len(c.currentInstructions()))
+		c.setSymbol(c.symbols.Define(node.Name.Value))
+
+		if node.Finally != nil {
+			setupTryPos = c.emit(operation.SetupTry, 9999)
+			c.enterTry(node.Finally)
+		}
+		err = c.Compile(node.Catch)
+		if err != nil {
+			return err
+		}
+		if node.Finally != nil {
+			c.leaveTry()
+			c.emit(operation.PopTry)
+		}
+		c.changeOperand(jumpPos, len(c.currentInstructions()))
+	}
+
+	if node.Finally == nil {
+		return nil
+	}
+
+	err = c.Compile(node.Finally)
+	if err != nil {
+		return err
+	}
+	jumpPos := c.emit(operation.Jump, 9999)
+
+	// The exception is left on the stack while the finally block runs
+	c.changeOperand(setupTryPos, len(c.currentInstructions()))
+	err = c.Compile(node.Finally)
+	if err != nil {
+		return err
+	}
+	c.emit(operation.Throw)
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+	return nil
+}
+
+// Compiles an import, binding the module (or its exports) in the current scope
+func (c *Compiler) compileImport(node *ast.ImportStatement) error {
+	symbol, err := c.compileModule(node)
+	if err != nil {
+		return err
+	}
+
+	if node.Name != nil {
+		c.emit(operation.GetGlobal, symbol.Index)
+		c.setSymbol(c.symbols.Define(node.Name.Value))
+		return nil
+	}
+
+	for _, name := range node.Names {
+		c.emit(operation.GetGlobal, symbol.Index)
+		member := &data.String{Value: name.Value}
+		c.emit(operation.Constant, c.addConstant(member))
+		c.emit(operation.Index)
+		c.setSymbol(c.symbols.Define(name.Value))
+	}
+	return nil
+}
+
+// Compiles an imported module as a function returning its exports, which runs on the first import only
+// Returns the global symbol the module is stored in
+func (c *Compiler) compileModule(node *ast.ImportStatement) (symbols.Symbol, error) {
+	file, err := c.loader.Resolve(node.Path, node.Position().File)
+	if err != nil {
+		return symbols.Symbol{}, fmt.Errorf("%s: %s", node.Position(), err)
+	}
+
+	// The name can't be an identifier, so it doesn't clash with the variables of the program
+	name := "import " + file
+	if symbol, ok := c.globals.Resolve(name); ok {
+		return symbol, nil
+	}
+
+	err = c.loader.Enter(file)
+	if err != nil {
+		return symbols.Symbol{}, fmt.Errorf("%s: %s", node.Position(), err)
+	}
+	defer c.loader.Leave()
+
+	program, err := c.loader.Parse(file)
+	if err != nil {
+		return symbols.Symbol{}, fmt.Errorf("%s: %s", node.Position(), err)
+	}
+
+	// Each module has its own symbol table, its variables are locals of the module function
+	outer := c.symbols
+	c.enterScope()
+	c.symbols = symbols.NewEnclosed(newSymbolTable())
+
+	err = c.Compile(program)
+	if err != nil {
+		return symbols.Symbol{}, err
+	}
+
+	exports := module.Exports(program)
+	for _, export := range exports {
+		symbol, _ := c.symbols.Resolve(export)
+		c.emit(operation.Constant, c.addConstant(&data.String{Value: export}))
+		c.loadSymbol(symbol)
+	}
+	c.emit(operation.Module, c.addConstant(&data.String{Value: node.Path}), len(exports)*2)
+	c.emit(operation.ReturnValue)
+
+	localCount := c.symbols.DefinitionCount
+	positions := c.scopes[c.currentScope].positions
+	instructions := c.leaveScope()
+	c.symbols = outer
+	if c.optimization >= O1 {
+		instructions, positions = c.optimize(instructions, positions)
+	}
+
+	compiled := &data.CompiledFunction{
+		Instructions: instructions,
+		LocalCount:   localCount,
+		Name:         "<module " + node.Path + ">",
+		Positions:    positions,
+	}
+	c.emit(operation.Closure, c.addConstant(compiled), 0)
+	c.emit(operation.Call, 0)
+
+	symbol := c.globals.Define(name)
+	c.emit(operation.SetGlobal, symbol.Index)
+	return symbol, nil
+}
+
+// Checks whether a block ends with an expression statement (whose value is left by removing its pop)
+func endsWithExpression(block *ast.BlockStatement) bool {
+	_, ok := lastStatement(block).(*ast.ExpressionStatement)
+	return ok
+}
+
+// Returns the last statement of a block, or nil for empty blocks
+func lastStatement(block *ast.BlockStatement) ast.Statement {
+	if len(block.Statements) == 0 {
+		return nil
+	}
+	return block.Statements[len(block.Statements)-1]
+}
+
+// Turns the calls whose result is returned right away (ex. `return f(x)`, or the last expression of a branch) into tail calls
+// The return following them is kept, as the jumps to it still need it
+func markTailCalls(ins operation.Instruction) {
+	for i := 0; i < len(ins); {
+		op, err := operation.Lookup(ins[i])
+		if err != nil {
+			return
+		}
+		_, read := operation.ReadOperands(op, ins[i+1:])
+		next := i + 1 + read
+
+		if operation.Opcode(ins[i]) == operation.Call && returnsAt(ins, next) {
+			ins[i] = byte(operation.TailCall)
+		}
+		i = next
+	}
+}
+
+// Checks whether the instruction at the given offset returns the value on top of the stack, following the jumps to it
+func returnsAt(ins operation.Instruction, offset int) bool {
+	// Jumps may go backward (ex. at the end of a loop body) and form cycles, which the bound on the steps stops following
+	for steps := 0; offset < len(ins) && steps < len(ins); steps++ {
+		switch operation.Opcode(ins[offset]) {
+		case operation.ReturnValue:
+			return true
+		case operation.Jump:
+			offset = int(operation.ReadUint16(ins[offset+1:]))
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// The binary operations used by the compound assignment operators
+var assignOperations = map[string]operation.Opcode{
+	"+": operation.Add,
+	"-": operation.Sub,
+	"*": operation.Mul,
+	"/": operation.Div,
+	"%": operation.Mod,
+}
+
+// Compiles an assignment, leaving the assigned value on the stack
+func (c *Compiler) compileAssign(node *ast.AssignExpression) error {
+	op, compound := assignOperations[node.BinaryOperator()]
+	if node.Operator != "=" && !compound {
+		return fmt.Errorf("%s: unknown operator %s", node.Position(), node.Operator)
+	}
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		symbol, ok := c.symbols.Resolve(target.Value)
+		if !ok {
+			return fmt.Errorf("%s: Variable %s is undefined", target.Position(), target.Value)
+		}
+		if symbol.Scope == symbols.BuiltinScope {
+			return fmt.Errorf("%s: Cannot assign to builtin %s", target.Position(), target.Value)
+		}
+
+		if compound {
+			c.loadSymbol(symbol)
+		}
+		err := c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+		if compound {
+			c.emit(op)
+		}
+
+		c.setSymbol(symbol)
+		c.loadSymbol(symbol)
+
+	case *ast.IndexExpression:
+		err := c.Compile(target.Left)
+		if err != nil {
+			return err
+		}
+		err = c.Compile(target.Index)
+		if err != nil {
+			return err
+		}
+		err = c.Compile(node.Value)
+		if err != nil {
+			return err
+		}
+
+		if compound {
+			c.emit(operation.UpdateIndex, int(op))
+		} else {
+			c.emit(operation.SetIndex)
+		}
+
+	default:
+		return fmt.Errorf("%s: Invalid assignment target %s", node.Position(), node.Target)
+	}
+
+	return nil
+}
+
 // Bytecode produces bytecode out of the compiler result
+// The optimizations work on copies of the constants, so the compiler is left unchanged (and can keep compiling)
 func (c *Compiler) Bytecode() *Bytecode {
+	instructions := c.currentInstructions()
+	positions := c.scopes[c.currentScope].positions
+	if c.optimization < O1 {
+		return &Bytecode{Instructions: instructions, Constants: c.constants, Positions: positions}
+	}
+
+	constants, constantKeys := c.constants, c.constantKeys
+	defer func() { c.constants, c.constantKeys = constants, constantKeys }()
+	c.constants = append([]data.Data{}, constants...)
+	c.constantKeys = maps.Clone(constantKeys)
+
+	instructions, positions = c.optimize(instructions, positions)
+	instructions = c.removeUnusedConstants(instructions)
 	return &Bytecode{
-		Instructions: c.currentInstructions(),
+		Instructions: instructions,
 		Constants:    c.constants,
+		Positions:    positions,
 	}
 }
 
 // Adds a constant to the constant pool and returns its index so it can be referenced
+// When optimizing, equal numbers share the same constant
 func (c *Compiler) addConstant(d data.Data) int {
+	var key constantKey
+	dedupe := c.optimization >= O1
+	switch d := d.(type) {
+	case *data.Integer:
+		key = constantKey{data.INTEGER_TYPE, uint64(d.Value)}
+	case *data.Float:
+		key = constantKey{data.FLOAT_TYPE, math.Float64bits(d.Value)}
+	default:
+		dedupe = false
+	}
+
+	if dedupe {
+		if index, ok := c.constantKeys[key]; ok {
+			return index
+		}
+		if c.constantKeys == nil {
+			c.constantKeys = map[constantKey]int{}
+		}
+		c.constantKeys[key] = len(c.constants)
+	}
+
 	c.constants = append(c.constants, d)
 	return len(c.constants) - 1
 }
@@ -345,6 +870,8 @@ func (c *Compiler) addConstant(d data.Data) int {
 // Adds an instruction to the instruction list (of the current scope) and returns its index
 func (c *Compiler) addInstruction(ins []byte) int {
 	pos := len(c.currentInstructions())
+	c.addPosition(pos)
+
 	instructions := append(c.currentInstructions(), ins...)
 	c.scopes[c.currentScope].instructions = instructions
 
@@ -380,6 +907,27 @@ func (c *Compiler) preventPop() {
 
 	c.scopes[c.currentScope].instructions = new
 	c.scopes[c.currentScope].emitted = c.scopes[c.currentScope].prevEmitted
+
+	// Drop the positions of the removed instruction
+	positions := c.scopes[c.currentScope].positions
+	for len(positions) > 0 && positions[len(positions)-1].Offset >= len(new) {
+		positions = positions[:len(positions)-1]
+	}
+	c.scopes[c.currentScope].positions = positions
+}
+
+// Sets the position of the node being compiled
+func (c *Compiler) setPosition(pos token.Position) {
+	c.position = pos
+}
+
+// Maps the instruction at the given offset to the position of the node being compiled
+func (c *Compiler) addPosition(offset int) {
+	positions := c.scopes[c.currentScope].positions
+	if len(positions) > 0 && positions[len(positions)-1].Position == c.position {
+		return
+	}
+	c.scopes[c.currentScope].positions = append(positions, data.SourcePosition{Offset: offset, Position: c.position})
 }
 
 // Changes an instruction at a given position to a given instruction
@@ -434,6 +982,85 @@ func (c *Compiler) leaveScope() operation.Instruction {
 	return instructions
 }
 
+// Enters a new loop, whose `continue` statements jump to the given position
+func (c *Compiler) enterLoop(start int, iterator bool) {
+	loop := &Loop{start: start, iterator: iterator, tries: len(c.scopes[c.currentScope].tries)}
+	c.scopes[c.currentScope].loops = append(c.scopes[c.currentScope].loops, loop)
+}
+
+// Leaves the current loop, making its `break` statements jump to the given position
+func (c *Compiler) leaveLoop(end int) {
+	loops := c.scopes[c.currentScope].loops
+	for _, pos := range loops[len(loops)-1].breaks {
+		c.changeOperand(pos, end)
+	}
+	c.scopes[c.currentScope].loops = loops[:len(loops)-1]
+}
+
+// Returns the innermost loop of the current scope, or nil outside of loops
+func (c *Compiler) currentLoop() *Loop {
+	loops := c.scopes[c.currentScope].loops
+	if len(loops) == 0 {
+		return nil
+	}
+	return loops[len(loops)-1]
+}
+
+// Enters an exception handler, whose finally block (if any) runs when it is left early
+func (c *Compiler) enterTry(finally *ast.BlockStatement) {
+	try := &Try{finally: finally}
+	c.scopes[c.currentScope].tries = append(c.scopes[c.currentScope].tries, try)
+}
+
+// Leaves the innermost exception handler
+func (c *Compiler) leaveTry() {
+	tries := c.scopes[c.currentScope].tries
+	c.scopes[c.currentScope].tries = tries[:len(tries)-1]
+}
+
+// Removes the exception handlers above the given depth (running their finally blocks), used when jumping out of them
+func (c *Compiler) exitTries(depth int) error {
+	tries := c.scopes[c.currentScope].tries
+	defer func() { c.scopes[c.currentScope].tries = tries }()
+
+	for i := len(tries) - 1; i >= depth; i-- {
+		c.emit(operation.PopTry)
+		if tries[i].finally == nil {
+			continue
+		}
+
+		// The finally block is compiled outside of its own handler
+		c.scopes[c.currentScope].tries = tries[:i]
+		err := c.Compile(tries[i].finally)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// For a given symbol emits the operation storing the value on top of the stack
+func (c *Compiler) setSymbol(s symbols.Symbol) {
+	switch s.Scope {
+	case symbols.GlobalScope:
+		c.emit(operation.SetGlobal, s.Index)
+	case symbols.LocalScope:
+		c.emit(operation.SetLocal, s.Index)
+	case symbols.FreeScope:
+		c.emit(operation.SetFree, s.Index)
+	}
+}
+
+// For a given captured symbol emits the operation loading its cell
+func (c *Compiler) loadCell(s symbols.Symbol) {
+	switch s.Scope {
+	case symbols.LocalScope:
+		c.emit(operation.GetLocalCell, s.Index)
+	case symbols.FreeScope:
+		c.emit(operation.GetFreeCell, s.Index)
+	}
+}
+
 // For a given symbol emits the corresponding operation depending on its scope
 func (c *Compiler) loadSymbol(s symbols.Symbol) {
 	switch s.Scope {
@@ -1,13 +1,19 @@
 package compiler
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ape-lang/ape/src/ast"
 	"github.com/ape-lang/ape/src/compiler/operation"
 	"github.com/ape-lang/ape/src/data"
 	"github.com/ape-lang/ape/src/lexer"
+	"github.com/ape-lang/ape/src/module"
 	"github.com/ape-lang/ape/src/parser"
 )
 
@@ -15,6 +21,7 @@ type compilerTestCase struct {
 	input                string
 	expectedConstants    []interface{}
 	expectedInstructions []operation.Instruction
+	optimization         int // O0 unless given
 }
 
 func TestIntegerArithmetic(t *testing.T) {
@@ -69,6 +76,66 @@ func TestIntegerArithmetic(t *testing.T) {
 				operation.NewInstruction(operation.Pop),
 			},
 		},
+		{
+			input:             "2 % 1",
+			expectedConstants: []interface{}{2, 1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Mod),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "2 & 1",
+			expectedConstants: []interface{}{2, 1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.BitAnd),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "2 | 1",
+			expectedConstants: []interface{}{2, 1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.BitOr),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "2 ^ 1",
+			expectedConstants: []interface{}{2, 1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.BitXor),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "2 << 1",
+			expectedConstants: []interface{}{2, 1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.ShiftLeft),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "2 >> 1",
+			expectedConstants: []interface{}{2, 1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.ShiftRight),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
 		{
 			input:             "-1",
 			expectedConstants: []interface{}{1},
@@ -78,6 +145,40 @@ func TestIntegerArithmetic(t *testing.T) {
 				operation.NewInstruction(operation.Pop),
 			},
 		},
+		{
+			input:             "~1",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.BitNot),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "1.5 + 2",
+			expectedConstants: []interface{}{1.5, 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Add),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "-0.5",
+			expectedConstants: []interface{}{0.5},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Minus),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
 	}
 	runCompilerTests(t, tests)
 }
@@ -112,11 +213,11 @@ func TestBooleanExpressions(t *testing.T) {
 		},
 		{
 			input:             "1 < 2",
-			expectedConstants: []interface{}{2, 1},
+			expectedConstants: []interface{}{1, 2},
 			expectedInstructions: []operation.Instruction{
 				operation.NewInstruction(operation.Constant, 0),
 				operation.NewInstruction(operation.Constant, 1),
-				operation.NewInstruction(operation.GreaterThan),
+				operation.NewInstruction(operation.LessThan),
 				operation.NewInstruction(operation.Pop),
 			},
 		},
@@ -169,6 +270,76 @@ func TestBooleanExpressions(t *testing.T) {
 				operation.NewInstruction(operation.Pop),
 			},
 		},
+		{
+			input:             "1 >= 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.GreaterThanOrEqual),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "1 <= 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.LessThanOrEqual),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+	}
+	runCompilerTests(t, tests)
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "true && false",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.True),
+				// 0001
+				operation.NewInstruction(operation.JumpNotTruthy, 10),
+				// 0004
+				operation.NewInstruction(operation.False),
+				// 0005
+				operation.NewInstruction(operation.Bang),
+				// 0006
+				operation.NewInstruction(operation.Bang),
+				// 0007
+				operation.NewInstruction(operation.Jump, 11),
+				// 0010
+				operation.NewInstruction(operation.False),
+				// 0011
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "true || false",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.True),
+				// 0001
+				operation.NewInstruction(operation.JumpNotTruthy, 8),
+				// 0004
+				operation.NewInstruction(operation.True),
+				// 0005
+				operation.NewInstruction(operation.Jump, 11),
+				// 0008
+				operation.NewInstruction(operation.False),
+				// 0009
+				operation.NewInstruction(operation.Bang),
+				// 0010
+				operation.NewInstruction(operation.Bang),
+				// 0011
+				operation.NewInstruction(operation.Pop),
+			},
+		},
 	}
 	runCompilerTests(t, tests)
 }
@@ -412,6 +583,22 @@ func TestIndexExpressions(t *testing.T) {
 				operation.NewInstruction(operation.Pop),
 			},
 		},
+		{
+			input:             `"ape"[1:]; "ape"[:2]`,
+			expectedConstants: []interface{}{"ape", 1, "ape", 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Null),
+				operation.NewInstruction(operation.Slice),
+				operation.NewInstruction(operation.Pop),
+				operation.NewInstruction(operation.Constant, 2),
+				operation.NewInstruction(operation.Null),
+				operation.NewInstruction(operation.Constant, 3),
+				operation.NewInstruction(operation.Slice),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
 	}
 	runCompilerTests(t, tests)
 }
@@ -736,7 +923,7 @@ func TestBuiltins(t *testing.T) {
 				[]operation.Instruction{
 					operation.NewInstruction(operation.GetBuiltin, 0),
 					operation.NewInstruction(operation.Array, 0),
-					operation.NewInstruction(operation.Call, 1),
+					operation.NewInstruction(operation.TailCall, 1),
 					operation.NewInstruction(operation.ReturnValue),
 				},
 			},
@@ -767,7 +954,7 @@ func TestClosures(t *testing.T) {
 					operation.NewInstruction(operation.ReturnValue),
 				},
 				[]operation.Instruction{
-					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.GetLocalCell, 0),
 					operation.NewInstruction(operation.Closure, 0, 1),
 					operation.NewInstruction(operation.ReturnValue),
 				},
@@ -797,13 +984,13 @@ func TestClosures(t *testing.T) {
 					operation.NewInstruction(operation.ReturnValue),
 				},
 				[]operation.Instruction{
-					operation.NewInstruction(operation.GetFree, 0),
-					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.GetFreeCell, 0),
+					operation.NewInstruction(operation.GetLocalCell, 0),
 					operation.NewInstruction(operation.Closure, 0, 2),
 					operation.NewInstruction(operation.ReturnValue),
 				},
 				[]operation.Instruction{
-					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.GetLocalCell, 0),
 					operation.NewInstruction(operation.Closure, 1, 1),
 					operation.NewInstruction(operation.ReturnValue),
 				},
@@ -848,15 +1035,15 @@ func TestClosures(t *testing.T) {
 				[]operation.Instruction{
 					operation.NewInstruction(operation.Constant, 2),
 					operation.NewInstruction(operation.SetLocal, 0),
-					operation.NewInstruction(operation.GetFree, 0),
-					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.GetFreeCell, 0),
+					operation.NewInstruction(operation.GetLocalCell, 0),
 					operation.NewInstruction(operation.Closure, 4, 2),
 					operation.NewInstruction(operation.ReturnValue),
 				},
 				[]operation.Instruction{
 					operation.NewInstruction(operation.Constant, 1),
 					operation.NewInstruction(operation.SetLocal, 0),
-					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.GetLocalCell, 0),
 					operation.NewInstruction(operation.Closure, 5, 1),
 					operation.NewInstruction(operation.ReturnValue),
 				},
@@ -872,56 +1059,1039 @@ func TestClosures(t *testing.T) {
 	runCompilerTests(t, tests)
 }
 
-// * HELPERS
-
-func runCompilerTests(t *testing.T, tests []compilerTestCase) {
-	t.Helper()
-
-	for _, tt := range tests {
-		program := parse(tt.input)
-		compiler := New()
-		err := compiler.Compile(program)
-
-		if err != nil {
-			t.Fatalf("compiler error: %s", err)
-		}
-
-		bytecode := compiler.Bytecode()
-
-		err = testInstructions(tt.expectedInstructions, bytecode.Instructions)
-		if err != nil {
-			t.Fatalf("testInstructions failed: %s", err)
-		}
-
-		err = testConstants(t, tt.expectedConstants, bytecode.Constants)
-		if err != nil {
-			t.Fatalf("testConstants failed: %s", err)
-		}
+func TestTailCalls(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input: `fn(f) { f() }`,
+			expectedConstants: []interface{}{
+				[]operation.Instruction{
+					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.TailCall, 0),
+					operation.NewInstruction(operation.ReturnValue),
+				},
+			},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 0, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input: `fn(f) { f() + 1 }`,
+			expectedConstants: []interface{}{
+				1,
+				[]operation.Instruction{
+					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.Call, 0),
+					operation.NewInstruction(operation.Constant, 0),
+					operation.NewInstruction(operation.Add),
+					operation.NewInstruction(operation.ReturnValue),
+				},
+			},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 1, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			// Both branches jump to the return
+			input: `fn(f) { if (f) { f() } else { f(1) } }`,
+			expectedConstants: []interface{}{
+				1,
+				[]operation.Instruction{
+					// 0000
+					operation.NewInstruction(operation.GetLocal, 0),
+					// 0002
+					operation.NewInstruction(operation.JumpNotTruthy, 12),
+					// 0005
+					operation.NewInstruction(operation.GetLocal, 0),
+					// 0007
+					operation.NewInstruction(operation.TailCall, 0),
+					// 0009
+					operation.NewInstruction(operation.Jump, 19),
+					// 0012
+					operation.NewInstruction(operation.GetLocal, 0),
+					// 0014
+					operation.NewInstruction(operation.Constant, 0),
+					// 0017
+					operation.NewInstruction(operation.TailCall, 1),
+					// 0019
+					operation.NewInstruction(operation.ReturnValue),
+				},
+			},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 1, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			// The call ending the loop body jumps back to the condition
+			input: `fn(f) { while (true) { f() } }`,
+			expectedConstants: []interface{}{
+				[]operation.Instruction{
+					// 0000
+					operation.NewInstruction(operation.True),
+					// 0001
+					operation.NewInstruction(operation.JumpNotTruthy, 12),
+					// 0004
+					operation.NewInstruction(operation.GetLocal, 0),
+					// 0006
+					operation.NewInstruction(operation.Call, 0),
+					// 0008
+					operation.NewInstruction(operation.Pop),
+					// 0009
+					operation.NewInstruction(operation.Jump, 0),
+					// 0012
+					operation.NewInstruction(operation.Return),
+				},
+			},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 0, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
 	}
-}
-
-// lexes and parses a program, returning an AST
-func parse(input string) *ast.Program {
-	l := lexer.New(input)
-	p := parser.New(l)
 
-	return p.ParseProgram()
-}
+	runCompilerTests(t, tests)
 
-// flattens the test expected instructions slice so it's comparable to the bytecode instructions slice
-func flattenInstructions(instructions []operation.Instruction) operation.Instruction {
-	result := operation.Instruction{}
-	for _, current := range instructions {
-		result = append(result, current...)
+	// A jump to itself never reaches a return
+	if cycle := operation.NewInstruction(operation.Jump, 0); returnsAt(cycle, 0) {
+		t.Errorf("expected a cycle of jumps not to return")
 	}
-	return result
 }
 
-// tests expected instructions
-func testInstructions(expected []operation.Instruction, actual operation.Instruction) error {
-	flattened := flattenInstructions(expected)
-	if len(actual) != len(flattened) {
-		return fmt.Errorf("wrong instructions length.\nwant=%q\ngot =%q", flattened, actual)
+func TestLoops(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "while (true) { 1 }",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.True),
+				// 0001
+				operation.NewInstruction(operation.JumpNotTruthy, 11),
+				// 0004
+				operation.NewInstruction(operation.Constant, 0),
+				// 0007
+				operation.NewInstruction(operation.Pop),
+				// 0008
+				operation.NewInstruction(operation.Jump, 0),
+			},
+		},
+		{
+			input:             "while (true) { break; continue; }",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.True),
+				// 0001
+				operation.NewInstruction(operation.JumpNotTruthy, 13),
+				// 0004
+				operation.NewInstruction(operation.Jump, 13),
+				// 0007
+				operation.NewInstruction(operation.Jump, 0),
+				// 0010
+				operation.NewInstruction(operation.Jump, 0),
+			},
+		},
+		{
+			input:             "for (let x in [1]) { x }",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.Constant, 0),
+				// 0003
+				operation.NewInstruction(operation.Array, 1),
+				// 0006
+				operation.NewInstruction(operation.Iterator),
+				// 0007
+				operation.NewInstruction(operation.IterNext, 20),
+				// 0010
+				operation.NewInstruction(operation.SetGlobal, 0),
+				// 0013
+				operation.NewInstruction(operation.GetGlobal, 0),
+				// 0016
+				operation.NewInstruction(operation.Pop),
+				// 0017
+				operation.NewInstruction(operation.Jump, 7),
+			},
+		},
+		{
+			input:             "for (let x in []) { break }",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.Array, 0),
+				// 0003
+				operation.NewInstruction(operation.Iterator),
+				// 0004
+				operation.NewInstruction(operation.IterNext, 17),
+				// 0007
+				operation.NewInstruction(operation.SetGlobal, 0),
+				// 0010
+				operation.NewInstruction(operation.Pop),
+				// 0011
+				operation.NewInstruction(operation.Jump, 17),
+				// 0014
+				operation.NewInstruction(operation.Jump, 4),
+			},
+		},
+		{
+			input:             "if (true) { while (false) {} }",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.True),
+				// 0001
+				operation.NewInstruction(operation.JumpNotTruthy, 15),
+				// 0004
+				operation.NewInstruction(operation.False),
+				// 0005
+				operation.NewInstruction(operation.JumpNotTruthy, 11),
+				// 0008
+				operation.NewInstruction(operation.Jump, 4),
+				// 0011
+				operation.NewInstruction(operation.Null),
+				// 0012
+				operation.NewInstruction(operation.Jump, 16),
+				// 0015
+				operation.NewInstruction(operation.Null),
+				// 0016
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "let x = 1; x = 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.SetGlobal, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.SetGlobal, 0),
+				operation.NewInstruction(operation.GetGlobal, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "let x = 1; x += 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.SetGlobal, 0),
+				operation.NewInstruction(operation.GetGlobal, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Add),
+				operation.NewInstruction(operation.SetGlobal, 0),
+				operation.NewInstruction(operation.GetGlobal, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "[1][0] = 2",
+			expectedConstants: []interface{}{1, 0, 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Array, 1),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Constant, 2),
+				operation.NewInstruction(operation.SetIndex),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "[1][0] %= 2",
+			expectedConstants: []interface{}{1, 0, 2},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Array, 1),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Constant, 2),
+				operation.NewInstruction(operation.UpdateIndex, int(operation.Mod)),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input: "fn(a) { a = 1; fn() { a -= 1 } }",
+			expectedConstants: []interface{}{
+				1,
+				1,
+				[]operation.Instruction{
+					operation.NewInstruction(operation.GetFree, 0),
+					operation.NewInstruction(operation.Constant, 1),
+					operation.NewInstruction(operation.Sub),
+					operation.NewInstruction(operation.SetFree, 0),
+					operation.NewInstruction(operation.GetFree, 0),
+					operation.NewInstruction(operation.ReturnValue),
+				},
+				[]operation.Instruction{
+					operation.NewInstruction(operation.Constant, 0),
+					operation.NewInstruction(operation.SetLocal, 0),
+					operation.NewInstruction(operation.GetLocal, 0),
+					operation.NewInstruction(operation.Pop),
+					operation.NewInstruction(operation.GetLocalCell, 0),
+					operation.NewInstruction(operation.Closure, 2, 1),
+					operation.NewInstruction(operation.ReturnValue),
+				},
+			},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 3, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestInvalidAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 1", "1:1: Variable x is undefined"},
+		{"len = 1", "1:1: Cannot assign to builtin len"},
+		{"fn() { y += 1 }", "1:8: Variable y is undefined"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		compiler := New()
+		err := compiler.Compile(program)
+		if err == nil {
+			t.Fatalf("expected compiler error but resulted in none.")
+		}
+
+		if err.Error() != tt.expected {
+			t.Fatalf("wrong compiler error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestTryStatements(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "try { 1 } catch (e) { e }",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.SetupTry, 11),
+				// 0003
+				operation.NewInstruction(operation.Constant, 0),
+				// 0006
+				operation.NewInstruction(operation.Pop),
+				// 0007
+				operation.NewInstruction(operation.PopTry),
+				// 0008
+				operation.NewInstruction(operation.Jump, 18),
+				// 0011
+				operation.NewInstruction(operation.SetGlobal, 0),
+				// 0014
+				operation.NewInstruction(operation.GetGlobal, 0),
+				// 0017
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			input:             "try { 1 } finally { 2 }",
+			expectedConstants: []interface{}{1, 2, 2},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.SetupTry, 15),
+				// 0003
+				operation.NewInstruction(operation.Constant, 0),
+				// 0006
+				operation.NewInstruction(operation.Pop),
+				// 0007
+				operation.NewInstruction(operation.PopTry),
+				// 0008
+				operation.NewInstruction(operation.Constant, 1),
+				// 0011
+				operation.NewInstruction(operation.Pop),
+				// 0012
+				operation.NewInstruction(operation.Jump, 20),
+				// 0015
+				operation.NewInstruction(operation.Constant, 2),
+				// 0018
+				operation.NewInstruction(operation.Pop),
+				// 0019
+				operation.NewInstruction(operation.Throw),
+			},
+		},
+		{
+			input: "fn() { try { return 1 } finally { 2 } }",
+			expectedConstants: []interface{}{
+				1,
+				2,
+				2,
+				2,
+				[]operation.Instruction{
+					// 0000
+					operation.NewInstruction(operation.SetupTry, 20),
+					// 0003
+					operation.NewInstruction(operation.Constant, 0),
+					// 0006
+					operation.NewInstruction(operation.PopTry),
+					// 0007
+					operation.NewInstruction(operation.Constant, 1),
+					// 0010
+					operation.NewInstruction(operation.Pop),
+					// 0011
+					operation.NewInstruction(operation.ReturnValue),
+					// 0012
+					operation.NewInstruction(operation.PopTry),
+					// 0013
+					operation.NewInstruction(operation.Constant, 2),
+					// 0016
+					operation.NewInstruction(operation.Pop),
+					// 0017
+					operation.NewInstruction(operation.Jump, 25),
+					// 0020
+					operation.NewInstruction(operation.Constant, 3),
+					// 0023
+					operation.NewInstruction(operation.Pop),
+					// 0024
+					operation.NewInstruction(operation.Throw),
+					// 0025
+					operation.NewInstruction(operation.Return),
+				},
+			},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 4, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestBytecodeIsRepeatable(t *testing.T) {
+	compiler := New()
+	compiler.SetOptimization(O1)
+	err := compiler.Compile(parse("let y = 1 + 2; let g = fn() { 10 * 20 }; g() + y"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	first := compiler.Bytecode()
+	second := compiler.Bytecode()
+	if first.Instructions.String() != second.Instructions.String() || len(first.Constants) != len(second.Constants) {
+		t.Errorf("the bytecode changed:\n%s\n%s", first.Instructions, second.Instructions)
+	}
+
+	// Compiling more afterwards still works with the constants compiled before
+	err = compiler.Compile(parse("y * 2"))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	third := compiler.Bytecode()
+	if len(third.Constants) <= len(first.Constants) {
+		t.Errorf("expected more constants, got %d", len(third.Constants))
+	}
+}
+
+func TestOptimizations(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "1 + 2 * 3",
+			expectedConstants: []interface{}{7},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			input:             "-(2.5 * 2) + 1",
+			expectedConstants: []interface{}{-4.0},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			input:             `"ape" + "lang"`,
+			expectedConstants: []interface{}{"apelang"},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			input:             "1 < 2 == !false",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.True),
+				operation.NewInstruction(operation.True),
+				operation.NewInstruction(operation.Equal),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			// Comparisons fold like the VM computes them, values of different types being unequal
+			input:             `"a" < "b"; 1 == "1"; 2 >= 2.0`,
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.True),
+				operation.NewInstruction(operation.Pop),
+				operation.NewInstruction(operation.False),
+				operation.NewInstruction(operation.Pop),
+				operation.NewInstruction(operation.True),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			// Operations raising errors are left to the VM
+			input:             "1 / 0; 1 << -1",
+			expectedConstants: []interface{}{1, 0, -1},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Div),
+				operation.NewInstruction(operation.Pop),
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Constant, 2),
+				operation.NewInstruction(operation.ShiftLeft),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			// Equal numbers share the same constant
+			input:             "let x = 1; x + 1.5 + 1 + 1.5",
+			expectedConstants: []interface{}{1, 1.5},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.SetGlobal, 0),
+				operation.NewInstruction(operation.GetGlobal, 0),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Add),
+				operation.NewInstruction(operation.Constant, 0),
+				operation.NewInstruction(operation.Add),
+				operation.NewInstruction(operation.Constant, 1),
+				operation.NewInstruction(operation.Add),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			input:             "let x = true; if (!x) { 10 }",
+			expectedConstants: []interface{}{10},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.True),
+				// 0001
+				operation.NewInstruction(operation.SetGlobal, 0),
+				// 0004
+				operation.NewInstruction(operation.GetGlobal, 0),
+				// 0007
+				operation.NewInstruction(operation.JumpTruthy, 16),
+				// 0010
+				operation.NewInstruction(operation.Constant, 0),
+				// 0013
+				operation.NewInstruction(operation.Jump, 17),
+				// 0016
+				operation.NewInstruction(operation.Null),
+				// 0017
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+		{
+			// The break jumps right after itself once the dead jump back to the condition is removed
+			input:             "let x = true; while (x) { break; }",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []operation.Instruction{
+				// 0000
+				operation.NewInstruction(operation.True),
+				// 0001
+				operation.NewInstruction(operation.SetGlobal, 0),
+				// 0004
+				operation.NewInstruction(operation.GetGlobal, 0),
+				// 0007
+				operation.NewInstruction(operation.JumpNotTruthy, 10),
+			},
+			optimization: O1,
+		},
+		{
+			// The constants only loaded by dead code are removed from the pool
+			input: "fn() { return 1 + 1; 2 }",
+			expectedConstants: []interface{}{
+				2,
+				[]operation.Instruction{
+					operation.NewInstruction(operation.Constant, 0),
+					operation.NewInstruction(operation.ReturnValue),
+				},
+			},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 1, 0),
+				operation.NewInstruction(operation.Pop),
+			},
+			optimization: O1,
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestLoopControlOutsideOfLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break;", "1:1: break outside of a loop"},
+		{"if (true) { continue; }", "1:13: continue outside of a loop"},
+		{"while (true) { fn() { break; } }", "1:23: break outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		compiler := New()
+		err := compiler.Compile(program)
+		if err == nil {
+			t.Fatalf("expected compiler error but resulted in none.")
+		}
+
+		if err.Error() != tt.expected {
+			t.Fatalf("wrong compiler error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestInstructionPositions(t *testing.T) {
+	input := "let add = fn(a, b) {\n  a + b\n};\nadd(1, 2);"
+
+	l := lexer.NewWithFile(input, "main.ape")
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	compiler := New()
+	err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	bytecode := compiler.Bytecode()
+
+	fn, ok := bytecode.Constants[0].(*data.CompiledFunction)
+	if !ok {
+		t.Fatalf("constant 0 is not a function: %T", bytecode.Constants[0])
+	}
+	if fn.Name != "add" {
+		t.Errorf("wrong function name: want=%q, got=%q", "add", fn.Name)
+	}
+
+	tests := []struct {
+		fn       *data.CompiledFunction
+		offset   int
+		expected string
+	}{
+		{fn, 0, "main.ape:2:3"}, // GetLocal 0
+		{fn, 4, "main.ape:2:5"}, // Add
+		{fn, 5, "main.ape:2:3"}, // ReturnValue
+		{&data.CompiledFunction{Positions: bytecode.Positions}, 0, "main.ape:1:11"}, // Closure
+		{&data.CompiledFunction{Positions: bytecode.Positions}, 4, "main.ape:1:1"},  // SetGlobal
+		{&data.CompiledFunction{Positions: bytecode.Positions}, 16, "main.ape:4:4"}, // Call
+	}
+
+	for _, tt := range tests {
+		actual := tt.fn.PositionAt(tt.offset).String()
+		if actual != tt.expected {
+			t.Errorf("wrong position at %d: want=%q, got=%q", tt.offset, tt.expected, actual)
+		}
+	}
+}
+
+func TestCompilerErrorPositions(t *testing.T) {
+	input := "let a = 1;\nlet b = a + c;"
+	expected := "main.ape:2:13: Variable c is undefined"
+
+	l := lexer.NewWithFile(input, "main.ape")
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	compiler := New()
+	err := compiler.Compile(program)
+	if err == nil {
+		t.Fatalf("expected compiler error but resulted in none.")
+	}
+
+	if err.Error() != expected {
+		t.Fatalf("wrong compiler error: want=%q, got=%q", expected, err)
+	}
+}
+
+func TestImports(t *testing.T) {
+	dir := writeModules(t, map[string]string{"m.ape": "export let x = 1;"})
+
+	moduleFn := []operation.Instruction{
+		operation.NewInstruction(operation.Constant, 0),
+		operation.NewInstruction(operation.SetLocal, 0),
+		operation.NewInstruction(operation.Constant, 1),
+		operation.NewInstruction(operation.GetLocal, 0),
+		operation.NewInstruction(operation.Module, 2, 2),
+		operation.NewInstruction(operation.ReturnValue),
+	}
+
+	tests := []compilerTestCase{
+		{
+			input:             `import "m"; m.x`,
+			expectedConstants: []interface{}{1, "x", "m", moduleFn, "x"},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 3, 0),
+				operation.NewInstruction(operation.Call, 0),
+				operation.NewInstruction(operation.SetGlobal, 0),
+				operation.NewInstruction(operation.GetGlobal, 0),
+				operation.NewInstruction(operation.SetGlobal, 1),
+				operation.NewInstruction(operation.GetGlobal, 1),
+				operation.NewInstruction(operation.Constant, 4),
+				operation.NewInstruction(operation.Index),
+				operation.NewInstruction(operation.Pop),
+			},
+		},
+		{
+			// The module is only compiled (and run) on the first import
+			input:             `import { x } from "m"; import "m";`,
+			expectedConstants: []interface{}{1, "x", "m", moduleFn, "x"},
+			expectedInstructions: []operation.Instruction{
+				operation.NewInstruction(operation.Closure, 3, 0),
+				operation.NewInstruction(operation.Call, 0),
+				operation.NewInstruction(operation.SetGlobal, 0),
+				operation.NewInstruction(operation.GetGlobal, 0),
+				operation.NewInstruction(operation.Constant, 4),
+				operation.NewInstruction(operation.Index),
+				operation.NewInstruction(operation.SetGlobal, 1),
+				operation.NewInstruction(operation.GetGlobal, 0),
+				operation.NewInstruction(operation.SetGlobal, 2),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		compiler := New()
+		compiler.SetLoader(module.NewLoader([]string{dir}))
+		err := compiler.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		bytecode := compiler.Bytecode()
+		err = testInstructions(tt.expectedInstructions, bytecode.Instructions)
+		if err != nil {
+			t.Fatalf("testInstructions failed: %s", err)
+		}
+		err = testConstants(t, tt.expectedConstants, bytecode.Constants)
+		if err != nil {
+			t.Fatalf("testConstants failed: %s", err)
+		}
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"a.ape":     `import "b";`,
+		"b.ape":     `import "./sub/c";`,
+		"sub/c.ape": `import "a";`,
+		"x.ape":     `let y = z;`,
+		"y.ape":     `export let y = secret;`,
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "a"`, "sub/c.ape:1:1: Import cycle: a.ape -> b.ape -> sub/c.ape -> a.ape"},
+		{`import "x"`, "x.ape:1:9: Variable z is undefined"},
+		{`let secret = 1; import "y"`, "y.ape:1:16: Variable secret is undefined"},
+		{`import "missing"`, "1:1: Module missing.ape not found (searched " + dir + ")"},
+	}
+
+	for _, tt := range tests {
+		compiler := New()
+		compiler.SetLoader(module.NewLoader([]string{dir}))
+		err := compiler.Compile(parse(tt.input))
+		if err == nil {
+			t.Fatalf("expected compiler error but resulted in none.")
+		}
+
+		// The module paths are shown relative to the directory
+		actual := filepath.ToSlash(strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), ""))
+		if actual != tt.expected {
+			t.Errorf("wrong compiler error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestBytecodeEncoding(t *testing.T) {
+	input := `let add = fn(a, b) { a + b };
+let greet = fn() { "hi" };
+add(1, -2.5);
+fn() { fn(x) { x * 1000000000000 } };`
+
+	l := lexer.NewWithFile(input, "main.ape")
+	program := parser.New(l).ParseProgram()
+	compiler := New()
+	err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	bytecode := compiler.Bytecode()
+
+	encoded, err := bytecode.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %s", err)
+	}
+
+	decoded := &Bytecode{}
+	err = decoded.UnmarshalBinary(encoded)
+	if err != nil {
+		t.Fatalf("UnmarshalBinary failed: %s", err)
+	}
+
+	if !decoded.Decoded() || bytecode.Decoded() {
+		t.Errorf("wrong origins: decoded=%t, compiled=%t", decoded.Decoded(), bytecode.Decoded())
+	}
+	bytecode.decoded = true
+	if !reflect.DeepEqual(decoded, bytecode) {
+		t.Errorf("decoded bytecode differs:\nwant=%+v\ngot=%+v", bytecode, decoded)
+	}
+}
+
+func TestBytecodeDecodingErrors(t *testing.T) {
+	bytecode := &Bytecode{
+		Instructions: operation.NewInstruction(operation.Constant, 0),
+		Constants:    []data.Data{&data.String{Value: "hello"}},
+	}
+	encoded, err := bytecode.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %s", err)
+	}
+
+	otherVersion := append([]byte{}, encoded...)
+	otherVersion[len(Magic)+1]++
+
+	tests := []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte("let x = 1;"), `not a compiled ape file (missing "APEC" signature)`},
+		{otherVersion, fmt.Sprintf("unsupported bytecode version %d (expected %d)", FormatVersion+1, FormatVersion)},
+		{encoded[:len(encoded)-1], "truncated bytecode"},
+		{encoded[:len(Magic)+3], "truncated bytecode"},
+		{append(encoded, 0), "unexpected 1 bytes after the bytecode"},
+	}
+
+	for _, tt := range tests {
+		err := (&Bytecode{}).UnmarshalBinary(tt.input)
+		if err == nil {
+			t.Fatalf("expected decoding error but resulted in none.")
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong decoding error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestBytecodeValidation(t *testing.T) {
+	concat := func(instructions ...operation.Instruction) operation.Instruction {
+		out := operation.Instruction{}
+		for _, ins := range instructions {
+			out = append(out, ins...)
+		}
+		return out
+	}
+	function := func(instructions operation.Instruction, locals int) *data.CompiledFunction {
+		return &data.CompiledFunction{Name: "f", Instructions: instructions, LocalCount: locals}
+	}
+
+	tests := []struct {
+		bytecode *Bytecode
+		expected string
+	}{
+		{&Bytecode{Instructions: operation.Instruction{255}},
+			"invalid function <main>: Undefined Opcode: 255 at 0"},
+		{&Bytecode{Instructions: operation.NewInstruction(operation.Constant, 0)[:2]},
+			"invalid function <main>: missing operands of Constant at 0"},
+		{&Bytecode{Instructions: operation.NewInstruction(operation.Constant, 1), Constants: []data.Data{&data.Integer{Value: 1}}},
+			"invalid function <main>: constant 1 out of range at 0"},
+		{&Bytecode{Instructions: operation.NewInstruction(operation.Closure, 0, 0), Constants: []data.Data{&data.Integer{Value: 1}}},
+			"invalid function <main>: constant 0 is not a function at 0"},
+		{&Bytecode{Instructions: operation.NewInstruction(operation.GetBuiltin, 255)},
+			"invalid function <main>: builtin 255 out of range at 0"},
+		{&Bytecode{Instructions: operation.NewInstruction(operation.GetLocal, 0)},
+			"invalid function <main>: local 0 out of range at 0"},
+		{&Bytecode{Instructions: concat(operation.NewInstruction(operation.Jump, 4), operation.NewInstruction(operation.Constant, 0)), Constants: []data.Data{&data.Integer{Value: 1}}},
+			"invalid function <main>: jump to 4 is not the start of an instruction"},
+		{&Bytecode{Instructions: operation.NewInstruction(operation.UpdateIndex, int(operation.Equal))},
+			fmt.Sprintf("invalid function <main>: invalid assignment operation %d at 0", operation.Equal)},
+		{&Bytecode{
+			Instructions: operation.NewInstruction(operation.Closure, 0, 1),
+			Constants:    []data.Data{function(concat(operation.NewInstruction(operation.GetFree, 1), operation.NewInstruction(operation.ReturnValue)), 0)},
+		}, "invalid function f: free variable 1 out of range at 0"},
+		{&Bytecode{
+			Constants: []data.Data{function(concat(operation.NewInstruction(operation.SetLocal, 2), operation.NewInstruction(operation.Return)), 2)},
+		}, "invalid function f: local 2 out of range at 0"},
+	}
+
+	for _, tt := range tests {
+		encoded, err := tt.bytecode.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary failed: %s", err)
+		}
+
+		err = (&Bytecode{}).UnmarshalBinary(encoded)
+		if err == nil {
+			t.Errorf("expected validation error %q but resulted in none.", tt.expected)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("wrong validation error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	input := `let add = fn(a, b) { a + b };
+let x = if (add(1, 2)) { "yes" } else { len([]) };
+fn(y) { fn() { y } };`
+
+	l := lexer.NewWithFile(input, "main.ape")
+	program := parser.New(l).ParseProgram()
+	compiler := New()
+	err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	sources := func(file string) (string, bool) {
+		return input, file == "main.ape"
+	}
+
+	expected := `== <main> (locals: 0, params: 0, free: 0) ==
+; main.ape:1 | let add = fn(a, b) { a + b };
+0000 Closure 0 0              ; fn add
+0004 SetGlobal 0
+; main.ape:2 | let x = if (add(1, 2)) { "yes" } else { len([]) };
+0007 GetGlobal 0
+0010 Constant 1               ; 1
+0013 Constant 2               ; 2
+0016 Call 2
+0018 JumpNotTruthy 27         ; L1
+0021 Constant 3               ; "yes"
+0024 Jump 34                  ; L2
+L1:
+0027 GetBuiltin 0             ; len
+0029 Array 0
+0032 Call 1
+L2:
+0034 SetGlobal 1
+; main.ape:3 | fn(y) { fn() { y } };
+0037 Closure 5 0              ; fn <anonymous>
+0041 Pop
+
+== fn add [constant 0] (locals: 2, params: 2, free: 0) ==
+; main.ape:1 | let add = fn(a, b) { a + b };
+0000 GetLocal 0
+0002 GetLocal 1
+0004 Add
+0005 ReturnValue
+
+== fn <anonymous> [constant 4] (locals: 0, params: 0, free: 1) ==
+; main.ape:3 | fn(y) { fn() { y } };
+0000 GetFree 0
+0002 ReturnValue
+
+== fn <anonymous> [constant 5] (locals: 1, params: 1, free: 0) ==
+; main.ape:3 | fn(y) { fn() { y } };
+0000 GetLocalCell 0
+0002 Closure 4 1              ; fn <anonymous>
+0006 ReturnValue
+`
+
+	var out bytes.Buffer
+	compiler.Bytecode().Disassemble(&out, sources)
+	if out.String() != expected {
+		t.Errorf("wrong disassembly.\nwant=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
+
+// * HELPERS
+
+func runCompilerTests(t *testing.T, tests []compilerTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		compiler := New()
+		compiler.SetOptimization(tt.optimization)
+		err := compiler.Compile(program)
+
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		bytecode := compiler.Bytecode()
+
+		err = testInstructions(tt.expectedInstructions, bytecode.Instructions)
+		if err != nil {
+			t.Fatalf("testInstructions failed: %s", err)
+		}
+
+		err = testConstants(t, tt.expectedConstants, bytecode.Constants)
+		if err != nil {
+			t.Fatalf("testConstants failed: %s", err)
+		}
+	}
+}
+
+// writes the given modules (by path relative to the directory) into a new temporary directory
+func writeModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, source := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// lexes and parses a program, returning an AST
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	return p.ParseProgram()
+}
+
+// flattens the test expected instructions slice so it's comparable to the bytecode instructions slice
+func flattenInstructions(instructions []operation.Instruction) operation.Instruction {
+	result := operation.Instruction{}
+	for _, current := range instructions {
+		result = append(result, current...)
+	}
+	return result
+}
+
+// tests expected instructions
+func testInstructions(expected []operation.Instruction, actual operation.Instruction) error {
+	flattened := flattenInstructions(expected)
+	if len(actual) != len(flattened) {
+		return fmt.Errorf("wrong instructions length.\nwant=%q\ngot =%q", flattened, actual)
 	}
 	for i, ins := range flattened {
 		if actual[i] != ins {
@@ -946,6 +2116,12 @@ func testConstants(t *testing.T, expected []interface{}, actual []data.Data) err
 				return fmt.Errorf("constant %d - testIntegerData failed: %s", i, err)
 			}
 
+		case float64:
+			err := testFloatData(constant, actual[i])
+			if err != nil {
+				return fmt.Errorf("constant %d - testFloatData failed: %s", i, err)
+			}
+
 		case string:
 			err := testStringData(constant, actual[i])
 			if err != nil {
@@ -981,6 +2157,19 @@ func testIntegerData(expected int64, actual data.Data) error {
 	return nil
 }
 
+func testFloatData(expected float64, actual data.Data) error {
+	result, ok := actual.(*data.Float)
+
+	if !ok {
+		return fmt.Errorf("data is not Float. got=%T (%+v)", actual, actual)
+	}
+
+	if result.Value != expected {
+		return fmt.Errorf("data has wrong value. got=%g, want=%g", result.Value, expected)
+	}
+	return nil
+}
+
 func testStringData(expected string, actual data.Data) error {
 	result, ok := actual.(*data.String)
 	if !ok {
@@ -0,0 +1,210 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ape-lang/ape/src/compiler/operation"
+	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/token"
+)
+
+// SourceFunc returns the source of a file (to show the lines alongside the instructions), ok is false when it is unavailable
+type SourceFunc func(file string) (source string, ok bool)
+
+// The opcodes whose first operand is the offset of a jump target
+var jumps = map[operation.Opcode]bool{
+	operation.Jump:          true,
+	operation.JumpNotTruthy: true,
+	operation.JumpTruthy:    true,
+	operation.IterNext:      true,
+	operation.SetupTry:      true,
+}
+
+// Disassemble writes a listing of the main program followed by every function in the constant pool
+// Constant operands are resolved inline, jump targets get labels and source lines are shown when sources has them (it may be nil)
+func (b *Bytecode) Disassemble(out io.Writer, sources SourceFunc) {
+	d := &disassembler{bytecode: b, out: out, sources: sources, lines: map[string][]string{}}
+
+	main := &data.CompiledFunction{Instructions: b.Instructions, Positions: b.Positions, Name: data.MainFunctionName}
+	fmt.Fprintf(out, "== %s (locals: 0, params: 0, free: 0) ==\n", main.Name)
+	d.function(main)
+
+	free := d.freeCounts()
+	for i, constant := range b.Constants {
+		fn, ok := constant.(*data.CompiledFunction)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(out, "\n== fn %s [constant %d] (locals: %d, params: %d, free: %d) ==\n",
+			functionName(fn), i, fn.LocalCount, fn.ParamCount, free[i])
+		d.function(fn)
+	}
+}
+
+type disassembler struct {
+	bytecode *Bytecode
+	out      io.Writer
+	sources  SourceFunc
+	lines    map[string][]string // the source lines of each file (nil if unavailable)
+}
+
+// Writes the instructions of a function, with labels before jump targets and source lines before each new line
+func (d *disassembler) function(fn *data.CompiledFunction) {
+	labels := labelTargets(fn.Instructions)
+	var last token.Position
+
+	for i := 0; i < len(fn.Instructions); {
+		if label, ok := labels[i]; ok {
+			fmt.Fprintf(d.out, "%s:\n", label)
+		}
+
+		pos := fn.PositionAt(i)
+		if pos.IsValid() && (pos.File != last.File || pos.Line != last.Line) {
+			d.sourceLine(pos)
+			last = pos
+		}
+
+		op, err := operation.Lookup(fn.Instructions[i])
+		if err != nil {
+			fmt.Fprintf(d.out, "%04d ERROR: %s\n", i, err)
+			i++
+			continue
+		}
+
+		operands, read := operation.ReadOperands(op, fn.Instructions[i+1:])
+		text := op.Name
+		for _, operand := range operands {
+			text += fmt.Sprintf(" %d", operand)
+		}
+
+		comment := d.comment(operation.Opcode(fn.Instructions[i]), operands, labels)
+		if comment == "" {
+			fmt.Fprintf(d.out, "%04d %s\n", i, text)
+		} else {
+			fmt.Fprintf(d.out, "%04d %-24s ; %s\n", i, text, comment)
+		}
+
+		i += 1 + read
+	}
+
+	// Jumps past the last instruction (to the end of the function)
+	if label, ok := labels[len(fn.Instructions)]; ok {
+		fmt.Fprintf(d.out, "%s:\n", label)
+	}
+}
+
+// Resolves the operands of an instruction into a readable comment (empty if there is nothing to add)
+func (d *disassembler) comment(opcode operation.Opcode, operands []int, labels map[int]string) string {
+	switch {
+	case jumps[opcode]:
+		return labels[operands[0]]
+	case opcode == operation.Constant, opcode == operation.Closure, opcode == operation.Module:
+		return d.constant(operands[0])
+	case opcode == operation.GetBuiltin:
+		if operands[0] < len(data.Builtins) {
+			return data.Builtins[operands[0]].Name
+		}
+	case opcode == operation.UpdateIndex:
+		if op, err := operation.Lookup(byte(operands[0])); err == nil {
+			return op.Name
+		}
+	}
+	return ""
+}
+
+// Formats the constant at the given index of the pool
+func (d *disassembler) constant(index int) string {
+	if index >= len(d.bytecode.Constants) {
+		return "<invalid constant>"
+	}
+	switch constant := d.bytecode.Constants[index].(type) {
+	case *data.String:
+		return fmt.Sprintf("%q", constant.Value)
+	case *data.CompiledFunction:
+		return "fn " + functionName(constant)
+	default:
+		return constant.Inspect()
+	}
+}
+
+// Writes the source line at the given position, or only the position if the source is unavailable
+func (d *disassembler) sourceLine(pos token.Position) {
+	lines, ok := d.lines[pos.File]
+	if !ok {
+		if d.sources != nil {
+			if source, found := d.sources(pos.File); found {
+				lines = strings.Split(source, "\n")
+			}
+		}
+		d.lines[pos.File] = lines
+	}
+
+	if pos.Line-1 < len(lines) {
+		fmt.Fprintf(d.out, "; %s:%d | %s\n", pos.File, pos.Line, strings.TrimSpace(lines[pos.Line-1]))
+	} else {
+		fmt.Fprintf(d.out, "; %s:%d\n", pos.File, pos.Line)
+	}
+}
+
+// Finds the free variable count of every function, given by the closures created from it
+func (d *disassembler) freeCounts() map[int]int {
+	free := map[int]int{}
+	scan := func(ins operation.Instruction) {
+		for i := 0; i < len(ins); {
+			op, err := operation.Lookup(ins[i])
+			if err != nil {
+				i++
+				continue
+			}
+			operands, read := operation.ReadOperands(op, ins[i+1:])
+			if operation.Opcode(ins[i]) == operation.Closure {
+				free[operands[0]] = operands[1]
+			}
+			i += 1 + read
+		}
+	}
+
+	scan(d.bytecode.Instructions)
+	for _, constant := range d.bytecode.Constants {
+		if fn, ok := constant.(*data.CompiledFunction); ok {
+			scan(fn.Instructions)
+		}
+	}
+	return free
+}
+
+// Names the jump targets of the instructions L1, L2... in the order they appear
+func labelTargets(ins operation.Instruction) map[int]string {
+	targets := []int{}
+	for i := 0; i < len(ins); {
+		op, err := operation.Lookup(ins[i])
+		if err != nil {
+			i++
+			continue
+		}
+		operands, read := operation.ReadOperands(op, ins[i+1:])
+		if jumps[operation.Opcode(ins[i])] {
+			targets = append(targets, operands[0])
+		}
+		i += 1 + read
+	}
+	sort.Ints(targets)
+
+	labels := map[int]string{}
+	for _, target := range targets {
+		if _, ok := labels[target]; !ok {
+			labels[target] = fmt.Sprintf("L%d", len(labels)+1)
+		}
+	}
+	return labels
+}
+
+func functionName(fn *data.CompiledFunction) string {
+	if fn.Name == "" {
+		return data.AnonymousFunctionName
+	}
+	return fn.Name
+}
@@ -0,0 +1,456 @@
+package compiler
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/ape-lang/ape/src/compiler/operation"
+	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/token"
+)
+
+// The optimization levels of the compiler
+const (
+	O0 = iota // No optimization, every node is compiled as is
+	O1        // Constant folding, peephole optimizations and deduplication of the constants
+)
+
+// An instruction being optimized
+type instruction struct {
+	opcode   operation.Opcode
+	operands []int
+	offset   int  // The offset in the unoptimized instructions (jump operands refer to these)
+	removed  bool // Removed instructions are skipped, jumps to them land on the next instruction left
+	position token.Position
+}
+
+// The instructions after which the next one only runs when jumped to
+var terminators = map[operation.Opcode]bool{
+	operation.Jump:        true,
+	operation.Return:      true,
+	operation.ReturnValue: true,
+	operation.Throw:       true,
+}
+
+// Optimizes the instructions of a function (or of the main program), returning them with their source positions
+// Constants computed while folding are added to the constant pool
+func (c *Compiler) optimize(ins operation.Instruction, positions []data.SourcePosition) (operation.Instruction, []data.SourcePosition) {
+	fn := &data.CompiledFunction{Instructions: ins, Positions: positions}
+	list := []*instruction{}
+	for i := 0; i < len(ins); {
+		op, err := operation.Lookup(ins[i])
+		if err != nil {
+			return ins, positions
+		}
+		operands, read := operation.ReadOperands(op, ins[i+1:])
+		list = append(list, &instruction{opcode: operation.Opcode(ins[i]), operands: operands, offset: i, position: fn.PositionAt(i)})
+		i += 1 + read
+	}
+
+	// Each rewrite can enable others (ex. folding an operand of another fold), so they run until nothing changes
+	for changed := true; changed; {
+		changed = c.fold(list) || removeDeadCode(list) || removeJumpsToNext(list) || collapseBangJumps(list)
+	}
+	return encode(list)
+}
+
+// Replaces operations on constants with their result
+func (c *Compiler) fold(list []*instruction) bool {
+	targets := jumpTargets(list)
+	live := liveInstructions(list)
+	changed := false
+
+	// The instructions replaced (except the first one) can't be jumped to
+	for i := 0; i < len(live); i++ {
+		if i+1 < len(live) && !targets[live[i+1]] {
+			if result, ok := c.foldUnary(live[i], live[i+1]); ok {
+				replace(live[i:i+2], result)
+				i++
+				changed = true
+				continue
+			}
+		}
+		if i+2 < len(live) && !targets[live[i+1]] && !targets[live[i+2]] {
+			if result, ok := c.foldBinary(live[i], live[i+1], live[i+2]); ok {
+				replace(live[i:i+3], result)
+				i += 2
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// Replaces a sequence of instructions with a single one, taking the place (and the position) of the first
+func replace(sequence []*instruction, ins *instruction) {
+	first := sequence[0]
+	*first = instruction{opcode: ins.opcode, operands: ins.operands, offset: first.offset, position: first.position}
+	for _, removed := range sequence[1:] {
+		removed.removed = true
+	}
+}
+
+// Folds a prefix operation on a constant operand
+func (c *Compiler) foldUnary(operand, op *instruction) (*instruction, bool) {
+	if op.opcode == operation.Bang {
+		switch operand.opcode {
+		case operation.True, operation.Constant:
+			// The constants are numbers and strings, which are truthy
+			return &instruction{opcode: operation.False}, true
+		case operation.False, operation.Null:
+			return &instruction{opcode: operation.True}, true
+		}
+		return nil, false
+	}
+
+	value := c.constantOperand(operand)
+	switch value := value.(type) {
+	case *data.Integer:
+		switch op.opcode {
+		case operation.Minus:
+			return c.constantInstruction(&data.Integer{Value: -value.Value}), true
+		case operation.BitNot:
+			return c.constantInstruction(&data.Integer{Value: ^value.Value}), true
+		}
+	case *data.Float:
+		if op.opcode == operation.Minus {
+			return c.constantInstruction(&data.Float{Value: -value.Value}), true
+		}
+	}
+	return nil, false
+}
+
+// Folds an infix operation on constant operands, the operations raising errors (ex. division by zero) are left to the VM
+func (c *Compiler) foldBinary(leftOperand, rightOperand, op *instruction) (*instruction, bool) {
+	left := c.constantOperand(leftOperand)
+	right := c.constantOperand(rightOperand)
+	if left == nil || right == nil {
+		return nil, false
+	}
+
+	if operator, ok := operation.ComparisonOperator(op.opcode); ok {
+		if result, ok := data.Comparison(operator, left, right); ok {
+			return c.constantInstruction(boolean(result)), true
+		}
+		return nil, false
+	}
+
+	leftInt, leftIsInt := left.(*data.Integer)
+	rightInt, rightIsInt := right.(*data.Integer)
+	leftFloat, leftIsNumber := data.NumberValue(left)
+	rightFloat, rightIsNumber := data.NumberValue(right)
+	leftString, leftIsString := left.(*data.String)
+	rightString, rightIsString := right.(*data.String)
+
+	switch {
+	case leftIsInt && rightIsInt:
+		if result, ok := foldIntegers(op.opcode, leftInt.Value, rightInt.Value); ok {
+			return c.constantInstruction(result), true
+		}
+	case leftIsNumber && rightIsNumber:
+		if result, ok := foldFloats(op.opcode, leftFloat, rightFloat); ok {
+			return c.constantInstruction(result), true
+		}
+	case leftIsString && rightIsString && op.opcode == operation.Add:
+		return c.constantInstruction(&data.String{Value: leftString.Value + rightString.Value}), true
+	}
+	return nil, false
+}
+
+// Computes an operation on integers the way the VM does
+func foldIntegers(op operation.Opcode, left, right int64) (data.Data, bool) {
+	switch op {
+	case operation.Add:
+		return &data.Integer{Value: left + right}, true
+	case operation.Sub:
+		return &data.Integer{Value: left - right}, true
+	case operation.Mul:
+		return &data.Integer{Value: left * right}, true
+	case operation.Div:
+		if right != 0 {
+			return &data.Integer{Value: left / right}, true
+		}
+	case operation.Mod:
+		if right != 0 {
+			return &data.Integer{Value: left % right}, true
+		}
+	case operation.BitAnd:
+		return &data.Integer{Value: left & right}, true
+	case operation.BitOr:
+		return &data.Integer{Value: left | right}, true
+	case operation.BitXor:
+		return &data.Integer{Value: left ^ right}, true
+	case operation.ShiftLeft:
+		if right >= 0 {
+			return &data.Integer{Value: left << uint64(right)}, true
+		}
+	case operation.ShiftRight:
+		if right >= 0 {
+			return &data.Integer{Value: left >> uint64(right)}, true
+		}
+	}
+	return nil, false
+}
+
+// Computes an operation on numbers (at least one of them a float) the way the VM does
+func foldFloats(op operation.Opcode, left, right float64) (data.Data, bool) {
+	switch op {
+	case operation.Add:
+		return &data.Float{Value: left + right}, true
+	case operation.Sub:
+		return &data.Float{Value: left - right}, true
+	case operation.Mul:
+		return &data.Float{Value: left * right}, true
+	case operation.Div:
+		return &data.Float{Value: left / right}, true
+	case operation.Mod:
+		return &data.Float{Value: math.Mod(left, right)}, true
+	}
+	return nil, false
+}
+
+// Returns the constant an instruction loads, or nil if it doesn't load a number or a string
+func (c *Compiler) constantOperand(ins *instruction) data.Data {
+	if ins.opcode != operation.Constant {
+		return nil
+	}
+	switch constant := c.constants[ins.operands[0]].(type) {
+	case *data.Integer, *data.Float, *data.String:
+		return constant
+	}
+	return nil
+}
+
+// Creates the instruction loading a folded value
+func (c *Compiler) constantInstruction(value data.Data) *instruction {
+	switch value {
+	case data.TRUE:
+		return &instruction{opcode: operation.True}
+	case data.FALSE:
+		return &instruction{opcode: operation.False}
+	}
+	return &instruction{opcode: operation.Constant, operands: []int{c.addConstant(value)}}
+}
+
+// Removes the instructions following a jump (or return) which can't be jumped to
+func removeDeadCode(list []*instruction) bool {
+	targets := jumpTargets(list)
+	changed := false
+	dead := false
+
+	for _, ins := range liveInstructions(list) {
+		if targets[ins] {
+			dead = false
+		}
+		if dead {
+			ins.removed = true
+			changed = true
+			continue
+		}
+		dead = terminators[ins.opcode]
+	}
+	return changed
+}
+
+// Removes the jumps to the instruction right after them
+func removeJumpsToNext(list []*instruction) bool {
+	live := liveInstructions(list)
+	changed := false
+
+	for i, ins := range live {
+		if ins.opcode != operation.Jump {
+			continue
+		}
+		target := resolve(list, ins.operands[0])
+		if (i+1 < len(live) && target == live[i+1]) || (i+1 == len(live) && target == nil) {
+			ins.removed = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Merges a negation followed by a conditional jump into the opposite jump
+func collapseBangJumps(list []*instruction) bool {
+	targets := jumpTargets(list)
+	live := liveInstructions(list)
+
+	for i := 0; i+1 < len(live); i++ {
+		bang, jump := live[i], live[i+1]
+		if bang.opcode != operation.Bang || targets[jump] {
+			continue
+		}
+
+		switch jump.opcode {
+		case operation.JumpNotTruthy:
+			replace(live[i:i+2], &instruction{opcode: operation.JumpTruthy, operands: jump.operands})
+		case operation.JumpTruthy:
+			replace(live[i:i+2], &instruction{opcode: operation.JumpNotTruthy, operands: jump.operands})
+		default:
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Returns the instructions which are not removed
+func liveInstructions(list []*instruction) []*instruction {
+	live := []*instruction{}
+	for _, ins := range list {
+		if !ins.removed {
+			live = append(live, ins)
+		}
+	}
+	return live
+}
+
+// Finds the instructions the jumps land on
+func jumpTargets(list []*instruction) map[*instruction]bool {
+	targets := map[*instruction]bool{}
+	for _, ins := range liveInstructions(list) {
+		if jumps[ins.opcode] {
+			if target := resolve(list, ins.operands[0]); target != nil {
+				targets[target] = true
+			}
+		}
+	}
+	return targets
+}
+
+// Returns the instruction a jump to the given (unoptimized) offset lands on, or nil at the end of the instructions
+func resolve(list []*instruction, offset int) *instruction {
+	i := sort.Search(len(list), func(i int) bool { return list[i].offset >= offset })
+	for ; i < len(list); i++ {
+		if !list[i].removed {
+			return list[i]
+		}
+	}
+	return nil
+}
+
+// Encodes the instructions left, updating the jumps and the source positions
+func encode(list []*instruction) (operation.Instruction, []data.SourcePosition) {
+	offsets := map[int]int{} // Maps the unoptimized offsets to the optimized ones
+	size := 0
+	for _, ins := range list {
+		offsets[ins.offset] = size
+		if !ins.removed {
+			size += len(operation.NewInstruction(ins.opcode, ins.operands...))
+		}
+	}
+
+	ins := operation.Instruction{}
+	positions := []data.SourcePosition{}
+	for _, i := range list {
+		if i.removed {
+			continue
+		}
+
+		operands := i.operands
+		if jumps[i.opcode] {
+			target, ok := offsets[operands[0]]
+			if !ok {
+				target = size
+			}
+			operands = []int{target}
+		}
+
+		if len(positions) == 0 || positions[len(positions)-1].Position != i.position {
+			positions = append(positions, data.SourcePosition{Offset: len(ins), Position: i.position})
+		}
+		ins = append(ins, operation.NewInstruction(i.opcode, operands...)...)
+	}
+	return ins, positions
+}
+
+// Returns the boolean data of a native bool
+func boolean(value bool) data.Data {
+	if value {
+		return data.TRUE
+	}
+	return data.FALSE
+}
+
+// The instructions whose first operand is a constant index
+var constantLoads = map[operation.Opcode]bool{
+	operation.Constant: true,
+	operation.Closure:  true,
+	operation.Module:   true,
+}
+
+// Removes the constants left unused by the optimizations from the pool, returning the main instructions with the updated indexes
+// Only the constants added by this compiler are removed, the previous ones are used by code compiled before (ex. in the REPL)
+func (c *Compiler) removeUnusedConstants(main operation.Instruction) operation.Instruction {
+	used := map[int]bool{}
+	var mark func(ins operation.Instruction)
+	mark = func(ins operation.Instruction) {
+		forEachConstantLoad(ins, func(operand []byte) {
+			index := int(operation.ReadUint16(operand))
+			if index < c.base || used[index] {
+				return
+			}
+			used[index] = true
+			// The functions are only used if they are loaded, along with the constants they load
+			if fn, ok := c.constants[index].(*data.CompiledFunction); ok {
+				mark(fn.Instructions)
+			}
+		})
+	}
+	mark(main)
+
+	indexes := map[int]int{}
+	constants := c.constants[:c.base:c.base]
+	for i := c.base; i < len(c.constants); i++ {
+		if used[i] {
+			indexes[i] = len(constants)
+			constants = append(constants, c.constants[i])
+		}
+	}
+	if len(constants) == len(c.constants) {
+		return main
+	}
+
+	reindex := func(ins operation.Instruction) operation.Instruction {
+		ins = append(operation.Instruction{}, ins...)
+		forEachConstantLoad(ins, func(operand []byte) {
+			if index, ok := indexes[int(operation.ReadUint16(operand))]; ok {
+				binary.BigEndian.PutUint16(operand, uint16(index))
+			}
+		})
+		return ins
+	}
+	for i, constant := range constants[c.base:] {
+		if fn, ok := constant.(*data.CompiledFunction); ok {
+			reindexed := *fn
+			reindexed.Instructions = reindex(fn.Instructions)
+			constants[c.base+i] = &reindexed
+		}
+	}
+	for key, index := range c.constantKeys {
+		if newIndex, ok := indexes[index]; ok {
+			c.constantKeys[key] = newIndex
+		} else if index >= c.base {
+			delete(c.constantKeys, key)
+		}
+	}
+
+	c.constants = constants
+	return reindex(main)
+}
+
+// Calls the function with the constant index operand of every instruction loading a constant
+func forEachConstantLoad(ins operation.Instruction, f func(operand []byte)) {
+	for i := 0; i < len(ins); {
+		op, err := operation.Lookup(ins[i])
+		if err != nil {
+			return
+		}
+		_, read := operation.ReadOperands(op, ins[i+1:])
+		if constantLoads[operation.Opcode(ins[i])] {
+			f(ins[i+1 : i+3])
+		}
+		i += 1 + read
+	}
+}
@@ -0,0 +1,149 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/ape-lang/ape/src/compiler/operation"
+	"github.com/ape-lang/ape/src/data"
+)
+
+// Checks that decoded bytecode only holds instructions the VM can execute: known opcodes with all their operands,
+// jumps to the start of an instruction, and constants, builtins, locals and free variables which exist
+func (b *Bytecode) validate() error {
+	// The free variables of the functions are the ones given by the closures created from them
+	freeCounts := map[int]int{}
+	functions := []*data.CompiledFunction{{Instructions: b.Instructions, Name: data.MainFunctionName}}
+	for i, constant := range b.Constants {
+		if fn, ok := constant.(*data.CompiledFunction); ok {
+			if fn.ParamCount > fn.LocalCount {
+				return fmt.Errorf("invalid function %s: %d parameters for %d locals", fn.Name, fn.ParamCount, fn.LocalCount)
+			}
+			freeCounts[i] = -1
+			functions = append(functions, fn)
+		}
+	}
+
+	for _, fn := range functions {
+		err := walkInstructions(fn.Instructions, func(pointer int, op operation.Opcode, operands []int) error {
+			if op != operation.Closure {
+				return nil
+			}
+			if count, ok := freeCounts[operands[0]]; ok && (count == -1 || operands[1] < count) {
+				freeCounts[operands[0]] = operands[1]
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("invalid function %s: %w", fn.Name, err)
+		}
+	}
+
+	for i, constant := range b.Constants {
+		if fn, ok := constant.(*data.CompiledFunction); ok {
+			if err := b.validateFunction(fn, max(freeCounts[i], 0)); err != nil {
+				return fmt.Errorf("invalid function %s: %w", fn.Name, err)
+			}
+		}
+	}
+	if err := b.validateFunction(functions[0], 0); err != nil {
+		return fmt.Errorf("invalid function %s: %w", data.MainFunctionName, err)
+	}
+	return nil
+}
+
+// Checks the operands of the instructions of a function
+func (b *Bytecode) validateFunction(fn *data.CompiledFunction, freeCount int) error {
+	starts := map[int]bool{len(fn.Instructions): true}
+	var jumps []int
+
+	err := walkInstructions(fn.Instructions, func(pointer int, op operation.Opcode, operands []int) error {
+		starts[pointer] = true
+
+		switch op {
+		case operation.Jump, operation.JumpNotTruthy, operation.JumpTruthy, operation.IterNext, operation.SetupTry:
+			jumps = append(jumps, operands[0])
+
+		case operation.Constant:
+			if operands[0] >= len(b.Constants) {
+				return fmt.Errorf("constant %d out of range at %d", operands[0], pointer)
+			}
+
+		case operation.Closure:
+			if operands[0] >= len(b.Constants) {
+				return fmt.Errorf("constant %d out of range at %d", operands[0], pointer)
+			}
+			if _, ok := b.Constants[operands[0]].(*data.CompiledFunction); !ok {
+				return fmt.Errorf("constant %d is not a function at %d", operands[0], pointer)
+			}
+
+		case operation.Module:
+			if operands[0] >= len(b.Constants) {
+				return fmt.Errorf("constant %d out of range at %d", operands[0], pointer)
+			}
+			if _, ok := b.Constants[operands[0]].(*data.String); !ok {
+				return fmt.Errorf("constant %d is not a module name at %d", operands[0], pointer)
+			}
+			if operands[1]%2 != 0 {
+				return fmt.Errorf("odd count of module exports at %d", pointer)
+			}
+
+		case operation.GetBuiltin:
+			if operands[0] >= len(data.Builtins) {
+				return fmt.Errorf("builtin %d out of range at %d", operands[0], pointer)
+			}
+
+		case operation.GetLocal, operation.SetLocal, operation.GetLocalCell:
+			if operands[0] >= fn.LocalCount {
+				return fmt.Errorf("local %d out of range at %d", operands[0], pointer)
+			}
+
+		case operation.GetFree, operation.SetFree, operation.GetFreeCell:
+			if operands[0] >= freeCount {
+				return fmt.Errorf("free variable %d out of range at %d", operands[0], pointer)
+			}
+
+		case operation.UpdateIndex:
+			switch operation.Opcode(operands[0]) {
+			case operation.Add, operation.Sub, operation.Mul, operation.Div, operation.Mod:
+			default:
+				return fmt.Errorf("invalid assignment operation %d at %d", operands[0], pointer)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, target := range jumps {
+		if !starts[target] {
+			return fmt.Errorf("jump to %d is not the start of an instruction", target)
+		}
+	}
+	return nil
+}
+
+// Calls a function for each instruction, failing on unknown opcodes and missing operands
+func walkInstructions(ins operation.Instruction, f func(pointer int, op operation.Opcode, operands []int) error) error {
+	for pointer := 0; pointer < len(ins); {
+		def, err := operation.Lookup(ins[pointer])
+		if err != nil {
+			return fmt.Errorf("%w at %d", err, pointer)
+		}
+
+		size := 0
+		for _, operandSize := range def.OperandSizes {
+			size += operandSize
+		}
+		if pointer+1+size > len(ins) {
+			return fmt.Errorf("missing operands of %s at %d", def.Name, pointer)
+		}
+
+		operands, _ := operation.ReadOperands(def, ins[pointer+1:])
+		if err := f(pointer, operation.Opcode(ins[pointer]), operands); err != nil {
+			return err
+		}
+		pointer += 1 + size
+	}
+	return nil
+}
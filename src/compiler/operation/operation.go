@@ -23,33 +23,60 @@ var operations = map[Opcode]*Operation{
 	Sub: {"Sub", []int{}},
 	Mul: {"Mul", []int{}},
 	Div: {"Div", []int{}},
+	Mod: {"Mod", []int{}},
+
+	// Bitwise
+	BitAnd:     {"BitAnd", []int{}},
+	BitOr:      {"BitOr", []int{}},
+	BitXor:     {"BitXor", []int{}},
+	ShiftLeft:  {"ShiftLeft", []int{}},
+	ShiftRight: {"ShiftRight", []int{}},
 
 	// Comparison
-	Equal:       {"Equal", []int{}},
-	NotEqual:    {"NotEqual", []int{}},
-	GreaterThan: {"GreaterThan", []int{}},
+	Equal:              {"Equal", []int{}},
+	NotEqual:           {"NotEqual", []int{}},
+	GreaterThan:        {"GreaterThan", []int{}},
+	GreaterThanOrEqual: {"GreaterThanOrEqual", []int{}},
+	LessThan:           {"LessThan", []int{}},
+	LessThanOrEqual:    {"LessThanOrEqual", []int{}},
 
 	// Prefix/Infix
-	Minus: {"Minus", []int{}},
-	Bang:  {"Bang", []int{}},
+	Minus:  {"Minus", []int{}},
+	Bang:   {"Bang", []int{}},
+	BitNot: {"BitNot", []int{}},
 
 	// Jumps
-	Jump:          {"Jump", []int{2}},          // Jump if value on top of stack truthy (to the given instruction)
+	Jump:          {"Jump", []int{2}},          // Jump (to the given instruction)
 	JumpNotTruthy: {"JumpNotTruthy", []int{2}}, // Jump if value on top of stack not truthy (to the given instruction)
+	JumpTruthy:    {"JumpTruthy", []int{2}},    // Jump if value on top of stack truthy (to the given instruction)
+
+	// Loops
+	Iterator: {"Iterator", []int{}},  // Replace the value on top of the stack with an iterator over it
+	IterNext: {"IterNext", []int{2}}, // Push the next value of the iterator, or pop it and jump (to the given instruction)
+
+	// Exceptions
+	SetupTry: {"SetupTry", []int{2}}, // Install an exception handler (jumping to the given instruction, with the exception on the stack)
+	PopTry:   {"PopTry", []int{}},    // Remove the innermost exception handler
+	Throw:    {"Throw", []int{}},     // Raise the exception on top of the stack
 
 	// Variables
 	GetGlobal: {"GetGlobal", []int{2}}, // Get a Global variable definition (at the given index)
-	SetGlobal: {"GetGlobal", []int{2}}, // Set a Global variable definition (with the given value)
+	SetGlobal: {"SetGlobal", []int{2}}, // Set a Global variable definition (with the given value)
 	GetLocal:  {"GetLocal", []int{1}},  // Get a Local variable definition (at the given index)
-	SetLocal:  {"GetLocal", []int{1}},  // Set a Local variable definition (with the given value)
+	SetLocal:  {"SetLocal", []int{1}},  // Set a Local variable definition (with the given value)
 
 	// Data Structures
 	Array: {"Array", []int{2}}, // Create an Array literal (with the given declaration)
 	Hash:  {"Hash", []int{2}},  // Create a Hash literal (with the given declaration)
 	Index: {"Index", []int{}},  // Index operator
+	Slice: {"Slice", []int{}},  // Slice operator (the collection, start and end are on the stack, null for a missing bound)
+
+	SetIndex:    {"SetIndex", []int{}},     // Set the index of a collection (the collection, index and value are on the stack)
+	UpdateIndex: {"UpdateIndex", []int{1}}, // Combine the index of a collection with a value (using the given binary opcode)
 
 	// Functions
 	Call:        {"Call", []int{1}},       // Call the function on top of the stack (with the given argument count)
+	TailCall:    {"TailCall", []int{1}},   // Call the function on top of the stack in place of the current one, whose result is the result of the call
 	Return:      {"Return", []int{}},      // Return nothing, exit the function and return nil
 	ReturnValue: {"ReturnValue", []int{}}, // Returns the value on top of the stack
 
@@ -59,6 +86,13 @@ var operations = map[Opcode]*Operation{
 	// Closures
 	Closure: {"Closure", []int{2, 1}}, // The first operand references the function, the second the free variable count (max 1 byte)
 	GetFree: {"GetFree", []int{1}},
+	SetFree: {"SetFree", []int{1}}, // Set a free variable (shared with the closures capturing it)
+
+	GetLocalCell: {"GetLocalCell", []int{1}}, // Get the cell of a Local variable (turning it into one), used when capturing it
+	GetFreeCell:  {"GetFreeCell", []int{1}},  // Get the cell of a free variable, used when capturing it again
+
+	// Modules
+	Module: {"Module", []int{2, 2}}, // Create a Module, the first operand references its name, the second is the count of export names and values on the stack
 }
 
 // Lookup looks up a given Opcode and returns the corresponding Operation
@@ -71,3 +105,23 @@ func Lookup(value byte) (*Operation, error) {
 	}
 	return operation, nil
 }
+
+// ComparisonOperator returns the infix operator of a comparison Opcode (ex. "<" for LessThan)
+func ComparisonOperator(opcode Opcode) (string, bool) {
+	switch opcode {
+	case Equal:
+		return "==", true
+	case NotEqual:
+		return "!=", true
+	case GreaterThan:
+		return ">", true
+	case GreaterThanOrEqual:
+		return ">=", true
+	case LessThan:
+		return "<", true
+	case LessThanOrEqual:
+		return "<=", true
+	default:
+		return "", false
+	}
+}
@@ -18,19 +18,41 @@ const (
 	Sub
 	Mul
 	Div
+	Mod
+
+	// Bitwise
+	BitAnd
+	BitOr
+	BitXor
+	ShiftLeft
+	ShiftRight
 
 	// Comparison
 	Equal
 	NotEqual
 	GreaterThan
+	GreaterThanOrEqual
+	LessThan
+	LessThanOrEqual
 
 	// Prefix/Infix
 	Minus
 	Bang
+	BitNot
 
 	// Jumps
 	Jump
 	JumpNotTruthy
+	JumpTruthy
+
+	// Loops
+	Iterator
+	IterNext
+
+	// Exceptions
+	SetupTry
+	PopTry
+	Throw
 
 	// Variables
 	GetGlobal
@@ -42,9 +64,13 @@ const (
 	Array
 	Hash
 	Index
+	Slice
+	SetIndex
+	UpdateIndex
 
 	// Functions
 	Call
+	TailCall
 	Return
 	ReturnValue
 
@@ -54,4 +80,10 @@ const (
 	// Closures
 	Closure
 	GetFree
+	SetFree
+	GetLocalCell
+	GetFreeCell
+
+	// Modules
+	Module
 )
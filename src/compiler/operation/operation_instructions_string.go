@@ -14,6 +14,7 @@ func (ins Instruction) String() string {
 		operation, err := Lookup(ins[i])
 		if err != nil {
 			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
 			continue
 		}
 
@@ -38,12 +38,16 @@ func TestInstructionString(t *testing.T) {
 		NewInstruction(Constant, 2),
 		NewInstruction(Constant, 65535),
 		NewInstruction(Closure, 65535, 255),
+		NewInstruction(SetLocal, 3),
+		NewInstruction(SetGlobal, 4),
 	}
 	expected := `0000 Add
 0001 GetLocal 1
 0003 Constant 2
 0006 Constant 65535
 0009 Closure 65535 255
+0013 SetLocal 3
+0015 SetGlobal 4
 `
 
 	result := Instruction{}
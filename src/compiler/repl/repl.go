@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/ape-lang/ape/src/compiler/compiler"
 	"github.com/ape-lang/ape/src/compiler/symbols"
@@ -17,7 +18,7 @@ func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 
 	constants := []data.Data{}
-	globals := make([]data.Data, vm.GlobalsLimit)
+	globals := []data.Data{}
 	symbols := symbols.New()
 	for i, v := range data.Builtins {
 		symbols.DefineBuiltin(i, v.Name)
@@ -37,7 +38,7 @@ func Start(in io.Reader, out io.Writer) {
 		program := p.ParseProgram()
 
 		if len(p.Errors()) != 0 {
-			printErrors(out, p.Errors())
+			printErrors(out, line, p.Errors())
 			continue
 		}
 
@@ -54,9 +55,10 @@ func Start(in io.Reader, out io.Writer) {
 
 		machine := vm.NewWithGlobals(bytecode, globals)
 		err = machine.Run()
+		globals = machine.Globals()
 
 		if err != nil {
-			fmt.Fprintf(out, "Execution failed:\n Error: %s\n", err)
+			printRuntimeError(out, err)
 			continue
 		}
 
@@ -67,12 +69,25 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
-func printErrors(out io.Writer, errors []string) {
+func printErrors(out io.Writer, source string, errors []*parser.ParseError) {
 	io.WriteString(out, "Input could not be parsed!\n")
 	io.WriteString(out, " Errors:\n")
 
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+	for _, err := range errors {
+		for _, line := range strings.Split(err.Render(source), "\n") {
+			io.WriteString(out, "\t"+line+"\n")
+		}
+	}
+}
+
+func printRuntimeError(out io.Writer, err error) {
+	fmt.Fprintf(out, "Execution failed:\n Error: %s\n", err)
+
+	if runtimeErr, ok := err.(*vm.RuntimeError); ok {
+		io.WriteString(out, " Stack trace:\n")
+		for _, line := range strings.Split(runtimeErr.StackTrace(), "\n") {
+			io.WriteString(out, "\t"+line+"\n")
+		}
 	}
 }
 
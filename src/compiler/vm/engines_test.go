@@ -0,0 +1,57 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/ape-lang/ape/src/compiler/compiler"
+	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/interpreter/eval"
+)
+
+// The programs must give the same result in the VM and the interpreter (the errors not caught included)
+func TestEnginesAgree(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let r = "no throw"; try { let x = len(1); r = x; } catch (e) { r = "caught " + e.message; }; r`,
+			`caught argument to 'len' not supported, got INTEGER`},
+		{`let r = ""; try { push(1, 2) } catch (e) { r = e.kind }; r`, `RuntimeError`},
+		{`len(1)`, `ERROR: argument to 'len' not supported, got INTEGER`},
+		{`let r = ""; try { map([1, 2], fn(x) { throw("boom") }) } catch (e) { r = e.message }; r`, `boom`},
+		{`1 / 0`, `ERROR: division by zero`},
+		{`let r = ""; try { 1 % 0 } catch (e) { r = e.message }; r`, `division by zero`},
+		{`[{1: "x"} == {1.0: "x"}, {1: "a"}[1.0], {1.0: "a", 1: "b"}]`, `[true, a, {1: b}]`},
+	}
+
+	for _, tt := range tests {
+		if got := evalResult(tt.input); got != tt.expected {
+			t.Errorf("wrong interpreter result for %q. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+		for _, level := range []int{compiler.O0, compiler.O1} {
+			if got := vmResult(t, tt.input, level); got != tt.expected {
+				t.Errorf("wrong vm result (O%d) for %q. expected=%q, got=%q", level, tt.input, tt.expected, got)
+			}
+		}
+	}
+}
+
+func evalResult(input string) string {
+	return eval.Eval(parse(input), data.NewEnvironment()).Inspect()
+}
+
+func vmResult(t *testing.T, input string, level int) string {
+	t.Helper()
+
+	comp := compiler.New()
+	comp.SetOptimization(level)
+	if err := comp.Compile(parse(input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	vm := New(comp.Bytecode())
+	if err := vm.Run(); err != nil {
+		return "ERROR: " + err.Error()
+	}
+	return vm.stack.popped().Inspect()
+}
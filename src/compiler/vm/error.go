@@ -0,0 +1,70 @@
+package vm
+
+import (
+	"errors"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+// ErrInvalidBytecode is wrapped by the error stopping a run of bytecode read from a compiled file whose instructions
+// failed (never caught by the program)
+var ErrInvalidBytecode = errors.New("invalid bytecode")
+
+// RuntimeError is an error raised while executing bytecode (and not caught by the program)
+type RuntimeError struct {
+	Message string
+	Trace   []data.TraceFrame // The calls active when the error was raised, innermost first
+
+	exception *data.Exception // The uncaught exception (to raise it again when a builtin called the function)
+}
+
+// Error returns the error message (without the stack trace)
+func (e *RuntimeError) Error() string {
+	return e.Message
+}
+
+// StackTrace returns the stack trace, one call per line
+func (e *RuntimeError) StackTrace() string {
+	exception := &data.Exception{Trace: e.Trace}
+	return exception.StackTrace()
+}
+
+// An exception raised by the program (ex. with `throw`)
+type thrownError struct {
+	exception *data.Exception
+}
+
+func (e *thrownError) Error() string {
+	return e.exception.Message
+}
+
+// Turns an error into an exception, recording the stack trace of the current frames (unless it already has one)
+func (vm *VM) exception(err error) *data.Exception {
+	if thrown, ok := err.(*thrownError); ok {
+		if thrown.exception.Trace == nil {
+			thrown.exception.Trace = vm.trace()
+		}
+		return thrown.exception
+	}
+
+	return &data.Exception{Kind: data.RuntimeErrorKind, Message: err.Error(), Trace: vm.trace()}
+}
+
+// Returns the stack trace of the current frames
+func (vm *VM) trace() []data.TraceFrame {
+	trace := make([]data.TraceFrame, 0, vm.frames.index)
+
+	for i := vm.frames.index - 1; i >= 0; i-- {
+		frame := vm.frames.items[i]
+		fn := frame.closure.Fn
+
+		name := fn.Name
+		if name == "" {
+			name = data.AnonymousFunctionName
+		}
+
+		trace = append(trace, data.TraceFrame{Function: name, Position: fn.PositionAt(frame.pointer)})
+	}
+
+	return data.CompactTrace(trace)
+}
@@ -1,6 +1,8 @@
 package vm
 
 import (
+	"fmt"
+
 	"github.com/ape-lang/ape/src/compiler/operation"
 	"github.com/ape-lang/ape/src/data"
 )
@@ -15,6 +17,7 @@ type Frame struct {
 type Frames struct {
 	items []*Frame
 	index int
+	max   int // The maximum number of frames (the depth of the calls)
 }
 
 // NewFrame creates a new frame for a given function
@@ -22,11 +25,12 @@ func NewFrame(cl *data.Closure, framePointer int) *Frame {
 	return &Frame{closure: cl, pointer: -1, framePointer: framePointer}
 }
 
-// NewFrames creates a collection of Frames
+// NewFrames creates a collection of Frames, growing on demand up to max
 func NewFrames(max int) *Frames {
 	return &Frames{
-		items: make([]*Frame, max),
+		items: []*Frame{},
 		index: 0,
+		max:   max,
 	}
 }
 
@@ -40,10 +44,19 @@ func (f *Frames) current() *Frame {
 	return f.items[f.index-1]
 }
 
-// push a frame into a frame collection
-func (f *Frames) push(frame *Frame) {
-	f.items[f.index] = frame
+// push a frame into a frame collection, failing once the maximum depth is reached
+func (f *Frames) push(frame *Frame) error {
+	if f.index >= f.max {
+		return fmt.Errorf("stack overflow")
+	}
+
+	if f.index < len(f.items) {
+		f.items[f.index] = frame
+	} else {
+		f.items = append(f.items, frame)
+	}
 	f.index++
+	return nil
 }
 
 // pop a frame from a frame collection
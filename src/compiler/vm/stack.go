@@ -8,14 +8,37 @@ import (
 
 // Stack contains the definition of the VM stack
 type Stack struct {
-	size    int
+	max     int // The size the stack can grow to
 	items   []data.Data
 	pointer int
 }
 
-// NewStack creates a new Stack
-func NewStack(size int) *Stack {
-	return &Stack{size: size, items: make([]data.Data, size), pointer: 0}
+// NewStack creates a new Stack with the given initial size, growing on demand up to max
+func NewStack(size int, max int) *Stack {
+	return &Stack{max: max, items: make([]data.Data, size), pointer: 0}
+}
+
+// grow makes room for the given number of items, at least doubling the size of the stack (up to its max)
+func (s *Stack) grow(size int) error {
+	if size <= len(s.items) {
+		return nil
+	}
+	if size > s.max {
+		return fmt.Errorf("stack overflow")
+	}
+
+	newSize := len(s.items) * 2
+	if newSize < size {
+		newSize = size
+	}
+	if newSize > s.max {
+		newSize = s.max
+	}
+
+	items := make([]data.Data, newSize)
+	copy(items, s.items)
+	s.items = items
+	return nil
 }
 
 // top returns the item on the top of the stack
@@ -29,8 +52,9 @@ func (s *Stack) top() data.Data {
 
 // push adds an item on the stack
 func (s *Stack) push(item data.Data) error {
-	if s.pointer >= s.size {
-		return fmt.Errorf("stack overflow")
+	err := s.grow(s.pointer + 1)
+	if err != nil {
+		return err
 	}
 
 	s.items[s.pointer] = item
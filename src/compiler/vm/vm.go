@@ -1,7 +1,10 @@
 package vm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime"
 
 	"github.com/ape-lang/ape/src/compiler/compiler"
 	"github.com/ape-lang/ape/src/compiler/operation"
@@ -9,8 +12,41 @@ import (
 )
 
 const GlobalsLimit = 65536 // equal to the max value represented by uint16 (operation.Constant)
-const StackLimit = 2048
-const FrameLimit = 1024
+
+// Config contains the sizes and limits of a VM
+// The sizes left zero (or negative) take their value from DefaultConfig, the zero Limits don't limit the runs
+type Config struct {
+	InitialStack int         // The initial size of the stack, which grows on demand
+	MaxStack     int         // The size the stack can grow to, a stack overflow error is raised past it
+	MaxFrames    int         // The maximum depth of the calls, a stack overflow error is raised past it
+	Globals      int         // The initial size of the globals, which grow on demand (up to GlobalsLimit)
+	Limits       data.Limits // The instructions and allocations a run may use (no limits by default)
+}
+
+// DefaultConfig is the configuration of the VMs created by New
+var DefaultConfig = Config{
+	InitialStack: 1024,
+	MaxStack:     1 << 20,
+	MaxFrames:    1 << 16,
+	Globals:      256,
+}
+
+// Returns the configuration with the sizes not set taken from DefaultConfig
+func (c Config) withDefaults() Config {
+	if c.InitialStack <= 0 {
+		c.InitialStack = DefaultConfig.InitialStack
+	}
+	if c.MaxStack <= 0 {
+		c.MaxStack = DefaultConfig.MaxStack
+	}
+	if c.MaxFrames <= 0 {
+		c.MaxFrames = DefaultConfig.MaxFrames
+	}
+	if c.Globals <= 0 {
+		c.Globals = DefaultConfig.Globals
+	}
+	return c
+}
 
 // VM contains the definition of the VM
 type VM struct {
@@ -18,45 +54,137 @@ type VM struct {
 	globals   []data.Data
 	stack     *Stack
 	frames    *Frames
+	handlers  []handler // The exception handlers installed by try statements, innermost last
+	limits    data.Limits
+	budget    *data.Budget // Set while running with a context or limits
+	decoded   bool         // Running bytecode read from a compiled file, whose failures are reported as invalid bytecode
 }
 
 // New creates a new VM from the given Bytecode
 func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithConfig(bytecode, DefaultConfig)
+}
+
+// NewWithConfig creates a new VM from the given Bytecode, with the given sizes and limits
+func NewWithConfig(bytecode *compiler.Bytecode, config Config) *VM {
+	config = config.withDefaults()
+
 	// create an execution frame for the main function
-	mainFn := &data.CompiledFunction{Instructions: bytecode.Instructions}
+	mainFn := &data.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		Name:         data.MainFunctionName,
+		Positions:    bytecode.Positions,
+	}
 	mainClosure := &data.Closure{Fn: mainFn}
 	mainFrame := NewFrame(mainClosure, 0)
 
-	frames := NewFrames(FrameLimit)
+	frames := NewFrames(config.MaxFrames)
 	frames.push(mainFrame)
 
 	return &VM{
 		constants: bytecode.Constants,
-		globals:   make([]data.Data, GlobalsLimit),
-		stack:     NewStack(StackLimit),
+		globals:   make([]data.Data, min(config.Globals, GlobalsLimit)),
+		stack:     NewStack(min(config.InitialStack, config.MaxStack), config.MaxStack),
 		frames:    frames,
+		limits:    config.Limits,
+		decoded:   bytecode.Decoded(),
 	}
 }
 
 // NewWithGlobals creates a new VM instance with closure over a globals array (for persistance)
+// The globals grow as the program defines them, so the ones to persist are returned by Globals
 func NewWithGlobals(bytecode *compiler.Bytecode, globals []data.Data) *VM {
 	vm := New(bytecode)
 	vm.globals = globals
 	return vm
 }
 
+// Globals returns the globals defined by the program (to pass them on to the next VM)
+func (vm *VM) Globals() []data.Data {
+	return vm.globals
+}
+
+// SetGlobals replaces the globals of the VM (to share them with other VMs, which must not grow them meanwhile)
+func (vm *VM) SetGlobals(globals []data.Data) {
+	vm.globals = globals
+}
+
 // Result returns the value of the last popped element from the stack (last evaluated expression)
 func (vm *VM) Result() data.Data {
 	return vm.stack.popped()
 }
 
 // Run executes every instruction given to the VM on creation
+// Errors are raised as exceptions, those not caught are returned as a *RuntimeError (carrying their stack trace)
 func (vm *VM) Run() error {
+	return vm.RunContext(context.Background())
+}
+
+// RunContext executes the instructions like Run, stopping once the context is done or the limits of the VM are exceeded
+// The returned error is then a *data.CanceledError, *data.StepLimitError or *data.AllocationLimitError (never caught by the program)
+func (vm *VM) RunContext(ctx context.Context) error {
+	if err := vm.startBudget(ctx); err != nil {
+		return err
+	}
+	return vm.execute()
+}
+
+// Starts tracking the resources used by the run (if it has a context to check or limits)
+func (vm *VM) startBudget(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &data.CanceledError{Err: err}
+	}
+
+	vm.budget = nil
+	if ctx.Done() != nil || vm.limits != (data.Limits{}) {
+		vm.budget = data.NewBudget(ctx, vm.limits)
+	}
+	return nil
+}
+
+// Runs the instructions until the current frame completes, handling the exceptions raised
+// Bytecode read from a compiled file (ex. a corrupted one) may still make the instructions fail, the run then stops
+// with an error wrapping ErrInvalidBytecode instead of crashing the program (other failures are bugs, left to crash)
+func (vm *VM) execute() (err error) {
+	if vm.decoded {
+		defer func() {
+			if r := recover(); r != nil {
+				failure, ok := r.(runtime.Error)
+				if !ok {
+					panic(r)
+				}
+				err = fmt.Errorf("%w: %s", ErrInvalidBytecode, failure)
+			}
+		}()
+	}
+
+	for {
+		err = vm.run()
+		if err == nil {
+			return nil
+		}
+		if data.IsLimitError(err) || errors.Is(err, ErrInvalidBytecode) {
+			return err
+		}
+
+		exception := vm.exception(err)
+		if !vm.catch(exception) {
+			return &RuntimeError{Message: exception.Message, Trace: exception.Trace, exception: exception}
+		}
+	}
+}
+
+func (vm *VM) run() error {
 	var pointer int
 	var instructions operation.Instruction
 	var op operation.Opcode
 
 	for vm.frames.current().pointer < len(vm.frames.current().Instructions())-1 {
+		if vm.budget != nil {
+			if err := vm.budget.Step(); err != nil {
+				return err
+			}
+		}
 		vm.frames.current().pointer++
 
 		pointer = vm.frames.current().pointer
@@ -99,14 +227,34 @@ func (vm *VM) Run() error {
 			if err != nil {
 				return err
 			}
+			err = vm.allocated()
+			if err != nil {
+				return err
+			}
 
-		case operation.Add, operation.Sub, operation.Mul, operation.Div:
+		case operation.BitNot:
+			err := vm.executeBitNotOp()
+			if err != nil {
+				return err
+			}
+			err = vm.allocated()
+			if err != nil {
+				return err
+			}
+
+		case operation.Add, operation.Sub, operation.Mul, operation.Div, operation.Mod,
+			operation.BitAnd, operation.BitOr, operation.BitXor, operation.ShiftLeft, operation.ShiftRight:
 			err := vm.executeBinaryOp(op)
 			if err != nil {
 				return err
 			}
+			err = vm.allocated()
+			if err != nil {
+				return err
+			}
 
-		case operation.Equal, operation.NotEqual, operation.GreaterThan:
+		case operation.Equal, operation.NotEqual, operation.GreaterThan, operation.GreaterThanOrEqual,
+			operation.LessThan, operation.LessThanOrEqual:
 			err := vm.executeComparison(op)
 			if err != nil {
 				return err
@@ -120,10 +268,50 @@ func (vm *VM) Run() error {
 			pos := int(operation.ReadUint16(instructions[pointer+1:]))
 			vm.frames.current().pointer += 2
 			condition := vm.stack.pop()
-			if !isTruthy(condition) {
+			if !data.IsTruthy(condition) {
+				vm.frames.current().pointer = pos - 1
+			}
+
+		case operation.JumpTruthy:
+			pos := int(operation.ReadUint16(instructions[pointer+1:]))
+			vm.frames.current().pointer += 2
+			condition := vm.stack.pop()
+			if data.IsTruthy(condition) {
+				vm.frames.current().pointer = pos - 1
+			}
+
+		case operation.Iterator:
+			err := vm.executeIterator()
+			if err != nil {
+				return err
+			}
+
+		case operation.IterNext:
+			pos := int(operation.ReadUint16(instructions[pointer+1:]))
+			vm.frames.current().pointer += 2
+
+			done, err := vm.executeIterNext()
+			if err != nil {
+				return err
+			}
+			if done {
 				vm.frames.current().pointer = pos - 1
 			}
 
+		case operation.SetupTry:
+			pos := int(operation.ReadUint16(instructions[pointer+1:]))
+			vm.frames.current().pointer += 2
+			vm.executeSetupTry(pos)
+
+		case operation.PopTry:
+			vm.executePopTry()
+
+		case operation.Throw:
+			err := vm.executeThrow()
+			if err != nil {
+				return err
+			}
+
 		case operation.Null:
 			err := vm.stack.push(data.NULL)
 			if err != nil {
@@ -133,12 +321,12 @@ func (vm *VM) Run() error {
 		case operation.SetGlobal:
 			index := operation.ReadUint16(instructions[pointer+1:])
 			vm.frames.current().pointer += 2
-			vm.globals[index] = vm.stack.pop()
+			vm.setGlobal(int(index), vm.stack.pop())
 
 		case operation.GetGlobal:
 			index := operation.ReadUint16(instructions[pointer+1:])
 			vm.frames.current().pointer += 2
-			err := vm.stack.push(vm.globals[index])
+			err := vm.stack.push(vm.global(int(index)))
 			if err != nil {
 				return err
 			}
@@ -147,13 +335,22 @@ func (vm *VM) Run() error {
 			localIndex := operation.ReadUint8(instructions[pointer+1:])
 			vm.frames.current().pointer++
 			frame := vm.frames.current()
-			vm.stack.items[frame.framePointer+int(localIndex)] = vm.stack.pop()
+			vm.setLocal(frame.framePointer+int(localIndex), vm.stack.pop())
 
 		case operation.GetLocal:
 			localIndex := operation.ReadUint8(instructions[pointer+1:])
 			vm.frames.current().pointer++
 			frame := vm.frames.current()
-			err := vm.stack.push(vm.stack.items[frame.framePointer+int(localIndex)])
+			err := vm.stack.push(vm.getLocal(frame.framePointer + int(localIndex)))
+			if err != nil {
+				return err
+			}
+
+		case operation.GetLocalCell:
+			localIndex := operation.ReadUint8(instructions[pointer+1:])
+			vm.frames.current().pointer++
+			frame := vm.frames.current()
+			err := vm.stack.push(vm.localCell(frame.framePointer + int(localIndex)))
 			if err != nil {
 				return err
 			}
@@ -167,6 +364,10 @@ func (vm *VM) Run() error {
 			if err != nil {
 				return err
 			}
+			err = vm.allocated()
+			if err != nil {
+				return err
+			}
 
 		case operation.Hash:
 			numElements := int(operation.ReadUint16(instructions[pointer+1:]))
@@ -180,6 +381,21 @@ func (vm *VM) Run() error {
 			if err != nil {
 				return err
 			}
+			err = vm.allocated()
+			if err != nil {
+				return err
+			}
+
+		case operation.Module:
+			nameIndex := operation.ReadUint16(instructions[pointer+1:])
+			numElements := int(operation.ReadUint16(instructions[pointer+3:]))
+			vm.frames.current().pointer += 4
+			module := vm.buildModule(vm.constants[nameIndex], vm.stack.pointer-numElements, vm.stack.pointer)
+			vm.stack.pointer = vm.stack.pointer - numElements
+			err := vm.stack.push(module)
+			if err != nil {
+				return err
+			}
 
 		case operation.Index:
 			index := vm.stack.pop()
@@ -189,6 +405,40 @@ func (vm *VM) Run() error {
 				return err
 			}
 
+		case operation.Slice:
+			end := vm.stack.pop()
+			start := vm.stack.pop()
+			left := vm.stack.pop()
+			slice, err := data.Slice(left, start, end)
+			if err != nil {
+				return err
+			}
+			err = vm.stack.push(slice)
+			if err != nil {
+				return err
+			}
+			err = vm.allocated()
+			if err != nil {
+				return err
+			}
+
+		case operation.SetIndex:
+			value := vm.stack.pop()
+			index := vm.stack.pop()
+			left := vm.stack.pop()
+			err := vm.executeSetIndex(left, index, value)
+			if err != nil {
+				return err
+			}
+
+		case operation.UpdateIndex:
+			op := operation.Opcode(operation.ReadUint8(instructions[pointer+1:]))
+			vm.frames.current().pointer++
+			err := vm.executeUpdateIndex(op)
+			if err != nil {
+				return err
+			}
+
 		case operation.Call:
 			// Get the number of arguments from the next instruction
 			argCount := operation.ReadUint8(instructions[pointer+1:])
@@ -199,6 +449,15 @@ func (vm *VM) Run() error {
 				return err
 			}
 
+		case operation.TailCall:
+			argCount := operation.ReadUint8(instructions[pointer+1:])
+			vm.frames.current().pointer++
+
+			err := vm.executeTailCall(int(argCount))
+			if err != nil {
+				return err
+			}
+
 		case operation.ReturnValue:
 			value := vm.stack.pop()
 			frame := vm.frames.pop()
@@ -233,8 +492,27 @@ func (vm *VM) Run() error {
 			if err != nil {
 				return err
 			}
+			err = vm.allocated()
+			if err != nil {
+				return err
+			}
 
 		case operation.GetFree:
+			freeIndex := operation.ReadUint8(instructions[pointer+1:])
+			vm.frames.current().pointer++
+			currentClosure := vm.frames.current().closure
+			err := vm.stack.push(currentClosure.Free[freeIndex].(*data.Cell).Value)
+			if err != nil {
+				return err
+			}
+
+		case operation.SetFree:
+			freeIndex := operation.ReadUint8(instructions[pointer+1:])
+			vm.frames.current().pointer++
+			currentClosure := vm.frames.current().closure
+			currentClosure.Free[freeIndex].(*data.Cell).Value = vm.stack.pop()
+
+		case operation.GetFreeCell:
 			freeIndex := operation.ReadUint8(instructions[pointer+1:])
 			vm.frames.current().pointer++
 			currentClosure := vm.frames.current().closure
@@ -247,15 +525,22 @@ func (vm *VM) Run() error {
 	return nil
 }
 
-func isTruthy(d data.Data) bool {
-	switch d := d.(type) {
-	case *data.Boolean:
-		return d.Value
-	case *data.Null:
-		return false
-	default:
-		return true
+// Returns the global at the given index (nil if it was never set)
+func (vm *VM) global(index int) data.Data {
+	if index >= len(vm.globals) {
+		return nil
+	}
+	return vm.globals[index]
+}
+
+// Sets the global at the given index, growing the globals if needed
+func (vm *VM) setGlobal(index int, value data.Data) {
+	if index >= len(vm.globals) {
+		globals := make([]data.Data, min(max(index+1, len(vm.globals)*2), GlobalsLimit))
+		copy(globals, vm.globals)
+		vm.globals = globals
 	}
+	vm.globals[index] = value
 }
 
 func (vm *VM) executeCall(argCount int) error {
@@ -279,22 +564,85 @@ func (vm *VM) callClosure(cl *data.Closure, argCount int) error {
 	}
 
 	frame := NewFrame(cl, vm.stack.pointer-argCount)
-	vm.frames.push(frame)
+	err := vm.stack.grow(frame.framePointer + cl.Fn.LocalCount)
+	if err != nil {
+		return err
+	}
+	err = vm.frames.push(frame)
+	if err != nil {
+		return err
+	}
 	vm.stack.pointer = frame.framePointer + cl.Fn.LocalCount
+
+	// Clear the local slots, as cells left by a previous call must not be written through
+	for i := frame.framePointer + argCount; i < vm.stack.pointer; i++ {
+		vm.stack.items[i] = nil
+	}
+	return nil
+}
+
+// Calls a function in place of the current one (which returns the result of the call), reusing its frame
+func (vm *VM) executeTailCall(argCount int) error {
+	cl, ok := vm.stack.items[vm.stack.pointer-1-argCount].(*data.Closure)
+	if !ok {
+		// Builtins don't use frames, so they are called as usual and the following return hands over their result
+		return vm.executeCall(argCount)
+	}
+	if argCount != cl.Fn.ParamCount {
+		return fmt.Errorf("wrong number of arguments: want=%d, got=%d", cl.Fn.ParamCount, argCount)
+	}
+
+	// Move the function and its arguments over the ones of the current function
+	frame := vm.frames.current()
+	err := vm.stack.grow(frame.framePointer + cl.Fn.LocalCount)
+	if err != nil {
+		return err
+	}
+	copy(vm.stack.items[frame.framePointer-1:], vm.stack.items[vm.stack.pointer-1-argCount:vm.stack.pointer])
+	frame.closure = cl
+	frame.pointer = -1
+	vm.stack.pointer = frame.framePointer + cl.Fn.LocalCount
+
+	// Clear the local slots, as cells left by a previous call must not be written through
+	for i := frame.framePointer + argCount; i < vm.stack.pointer; i++ {
+		vm.stack.items[i] = nil
+	}
 	return nil
 }
 
 func (vm *VM) callBuiltin(builtin *data.Builtin, argCount int) error {
 	args := vm.stack.items[vm.stack.pointer-argCount : vm.stack.pointer]
-	result := builtin.Fn(args...)
+	if err := builtin.Reserve(vm.budget, args); err != nil {
+		return err
+	}
+	result := builtin.Call(caller{vm: vm}, args...)
 	vm.stack.pointer = vm.stack.pointer - argCount - 1
 
+	// Errors are raised as exceptions (a RuntimeError, unless raised by `throw`), like in the interpreter, or stop the program
+	if err, ok := result.(*data.Error); ok {
+		if err.Fatal != nil {
+			return err.Fatal
+		}
+		if err.Exception != nil {
+			return &thrownError{exception: err.Exception}
+		}
+		return &thrownError{exception: &data.Exception{Kind: data.RuntimeErrorKind, Message: err.Message}}
+	}
+
 	if result != nil {
 		vm.stack.push(result)
 	} else {
 		vm.stack.push(data.NULL)
 	}
-	return nil
+	return vm.allocated()
+}
+
+// Counts the value on top of the stack (just created) against the allocation limit
+func (vm *VM) allocated() error {
+	if vm.budget == nil {
+		return nil
+	}
+	return vm.budget.Allocate(vm.stack.top())
 }
 
 func (vm *VM) pushClosure(constIndex int, freeCount int) error {
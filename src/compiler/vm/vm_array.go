@@ -7,5 +7,5 @@ func (vm *VM) buildArray(startIndex, endIndex int) data.Data {
 	for i := startIndex; i < endIndex; i++ {
 		elements[i-startIndex] = vm.stack.items[i]
 	}
-	return &data.Array{Elements: elements}
+	return data.NewArray(elements)
 }
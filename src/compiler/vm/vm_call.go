@@ -0,0 +1,89 @@
+package vm
+
+import (
+	"context"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+// NativeFunctionName is the name of the Go code (a builtin or the host program) calling a function in stack traces
+const NativeFunctionName = "<native>"
+
+// The function of the frames calling functions from Go, which have no instructions
+var native = &data.Closure{Fn: &data.CompiledFunction{Name: NativeFunctionName}}
+
+// Call calls a function (a closure or a builtin) with the given arguments and returns its result
+// It may be called while the VM is running (ex. by a builtin), in which case the call shares the budget of the run
+func (vm *VM) Call(fn data.Data, args ...data.Data) (data.Data, error) {
+	return vm.call(fn, args)
+}
+
+// CallContext calls a function like Call, stopping once the context is done or the limits of the VM are exceeded
+func (vm *VM) CallContext(ctx context.Context, fn data.Data, args ...data.Data) (data.Data, error) {
+	previous := vm.budget
+	defer func() { vm.budget = previous }()
+
+	if err := vm.startBudget(ctx); err != nil {
+		return nil, err
+	}
+	return vm.call(fn, args)
+}
+
+func (vm *VM) call(fn data.Data, args []data.Data) (data.Data, error) {
+	// The function is called from an empty frame, so the run stops once it returns
+	frames, pointer := vm.frames.index, vm.stack.pointer
+	err := vm.frames.push(NewFrame(native, pointer))
+	if err != nil {
+		return nil, err
+	}
+
+	// The handlers of the code calling the native code can't catch the exceptions of the call (they are returned to it)
+	handlers := vm.handlers
+	vm.handlers = nil
+	defer func() {
+		vm.handlers = handlers
+		vm.frames.index = frames
+		vm.stack.pointer = pointer
+	}()
+
+	err = vm.stack.push(fn)
+	for _, arg := range args {
+		if err != nil {
+			break
+		}
+		err = vm.stack.push(arg)
+	}
+	if err == nil {
+		err = vm.executeCall(len(args))
+	}
+	if err != nil {
+		exception := vm.exception(err)
+		return nil, &RuntimeError{Message: exception.Message, Trace: exception.Trace, exception: exception}
+	}
+
+	err = vm.execute()
+	if err != nil {
+		return nil, err
+	}
+	return vm.stack.top(), nil
+}
+
+// caller calls functions for the builtins run by a VM
+type caller struct {
+	vm *VM
+}
+
+func (c caller) Call(fn data.Data, args ...data.Data) data.Data {
+	result, err := c.vm.call(fn, args)
+	if err == nil {
+		return result
+	}
+
+	// The exception keeps its trace, so it is raised again as is by the builtin call
+	switch err := err.(type) {
+	case *RuntimeError:
+		return &data.Error{Message: err.Message, Exception: err.exception}
+	default:
+		return &data.Error{Message: err.Error(), Fatal: err}
+	}
+}
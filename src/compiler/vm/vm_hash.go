@@ -7,7 +7,7 @@ import (
 )
 
 func (vm *VM) buildHash(startIndex, endIndex int) (data.Data, error) {
-	hashedPairs := make(map[data.HashKey]data.HashPair)
+	hash := data.NewHash((endIndex - startIndex) / 2)
 	for i := startIndex; i < endIndex; i += 2 {
 		key := vm.stack.items[i]
 		value := vm.stack.items[i+1]
@@ -16,7 +16,7 @@ func (vm *VM) buildHash(startIndex, endIndex int) (data.Data, error) {
 		if !ok {
 			return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
 		}
-		hashedPairs[data.HashData(hashKey)] = pair
+		hash.Set(data.HashData(hashKey), pair)
 	}
-	return &data.Hash{Pairs: hashedPairs}, nil
+	return hash, nil
 }
@@ -3,6 +3,7 @@ package vm
 import (
 	"fmt"
 
+	"github.com/ape-lang/ape/src/compiler/operation"
 	"github.com/ape-lang/ape/src/data"
 )
 
@@ -11,9 +12,18 @@ func (vm *VM) executeIndexExpr(left, index data.Data) error {
 	case left.Type() == data.ARRAY_TYPE && index.Type() == data.INTEGER_TYPE:
 		return vm.executeArrayIndex(left, index)
 
+	case left.Type() == data.STRING_TYPE && index.Type() == data.INTEGER_TYPE:
+		return vm.stack.push(left.(*data.String).CharAt(index.(*data.Integer).Value))
+
 	case left.Type() == data.HASH_TYPE:
 		return vm.executeHashIndex(left, index)
 
+	case left.Type() == data.EXCEPTION_TYPE && index.Type() == data.STRING_TYPE:
+		return vm.executeExceptionIndex(left, index)
+
+	case left.Type() == data.MODULE_TYPE && index.Type() == data.STRING_TYPE:
+		return vm.executeModuleIndex(left, index)
+
 	default:
 		return fmt.Errorf("index operator not supported: %s", left.Type())
 	}
@@ -22,11 +32,11 @@ func (vm *VM) executeIndexExpr(left, index data.Data) error {
 func (vm *VM) executeArrayIndex(array, index data.Data) error {
 	arr := array.(*data.Array)
 	i := index.(*data.Integer).Value
-	max := int64(len(arr.Elements) - 1)
+	max := int64(arr.Len() - 1)
 	if i < 0 || i > max {
 		return vm.stack.push(data.NULL)
 	}
-	return vm.stack.push(arr.Elements[i])
+	return vm.stack.push(arr.Get(int(i)))
 }
 
 func (vm *VM) executeHashIndex(hash, index data.Data) error {
@@ -36,9 +46,73 @@ func (vm *VM) executeHashIndex(hash, index data.Data) error {
 		return fmt.Errorf("unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashObject.Pairs[data.HashData(key)]
+	pair, ok := hashObject.Get(data.HashData(key))
 	if !ok {
 		return vm.stack.push(data.NULL)
 	}
 	return vm.stack.push(pair.Value)
 }
+
+func (vm *VM) executeExceptionIndex(exception, index data.Data) error {
+	field, ok := exception.(*data.Exception).Field(index.(*data.String).Value)
+	if !ok {
+		return vm.stack.push(data.NULL)
+	}
+	return vm.stack.push(field)
+}
+
+func (vm *VM) executeSetIndex(left, index, value data.Data) error {
+	err := setIndex(left, index, value)
+	if err != nil {
+		return err
+	}
+	return vm.stack.push(value)
+}
+
+// Combines the indexed value with the value on top of the stack, using the given binary operation
+func (vm *VM) executeUpdateIndex(op operation.Opcode) error {
+	value := vm.stack.pop()
+	index := vm.stack.pop()
+	left := vm.stack.pop()
+
+	err := vm.executeIndexExpr(left, index)
+	if err != nil {
+		return err
+	}
+	err = vm.stack.push(value)
+	if err != nil {
+		return err
+	}
+	err = vm.executeBinaryOp(op)
+	if err != nil {
+		return err
+	}
+
+	return vm.executeSetIndex(left, index, vm.stack.pop())
+}
+
+func setIndex(left, index, value data.Data) error {
+	switch left := left.(type) {
+	case *data.Array:
+		i, ok := index.(*data.Integer)
+		if !ok {
+			return fmt.Errorf("array index must be INTEGER, got %s", index.Type())
+		}
+		if i.Value < 0 || i.Value >= int64(left.Len()) {
+			return fmt.Errorf("array index out of range: %d", i.Value)
+		}
+		left.Set(int(i.Value), value)
+		return nil
+
+	case *data.Hash:
+		key, ok := index.(data.HashableData)
+		if !ok {
+			return fmt.Errorf("unusable as hash key: %s", index.Type())
+		}
+		left.Set(data.HashData(key), data.HashPair{Key: index, Value: value})
+		return nil
+
+	default:
+		return fmt.Errorf("index assignment not supported: %s", left.Type())
+	}
+}
@@ -0,0 +1,29 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+func (vm *VM) executeIterator() error {
+	iterable := vm.stack.pop()
+
+	iterator, ok := data.NewIterator(iterable)
+	if !ok {
+		return fmt.Errorf("not iterable: %s", iterable.Type())
+	}
+	return vm.stack.push(iterator)
+}
+
+// Pushes the next value of the iterator on top of the stack, or pops the iterator once it is exhausted
+func (vm *VM) executeIterNext() (bool, error) {
+	iterator := vm.stack.top().(*data.Iterator)
+
+	value, ok := iterator.Next()
+	if !ok {
+		vm.stack.pop()
+		return true, nil
+	}
+	return false, vm.stack.push(value)
+}
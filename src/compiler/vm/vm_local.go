@@ -0,0 +1,30 @@
+package vm
+
+import "github.com/ape-lang/ape/src/data"
+
+// Returns the value of the local at the given stack index (reading through its cell if captured)
+func (vm *VM) getLocal(index int) data.Data {
+	if cell, ok := vm.stack.items[index].(*data.Cell); ok {
+		return cell.Value
+	}
+	return vm.stack.items[index]
+}
+
+// Sets the value of the local at the given stack index (writing through its cell if captured)
+func (vm *VM) setLocal(index int, value data.Data) {
+	if cell, ok := vm.stack.items[index].(*data.Cell); ok {
+		cell.Value = value
+		return
+	}
+	vm.stack.items[index] = value
+}
+
+// Returns the cell of the local at the given stack index, moving the local into a new cell if needed
+func (vm *VM) localCell(index int) *data.Cell {
+	if cell, ok := vm.stack.items[index].(*data.Cell); ok {
+		return cell
+	}
+	cell := &data.Cell{Value: vm.stack.items[index]}
+	vm.stack.items[index] = cell
+	return cell
+}
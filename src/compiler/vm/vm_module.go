@@ -0,0 +1,27 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+func (vm *VM) buildModule(name data.Data, startIndex, endIndex int) data.Data {
+	exports := make(map[string]data.Data)
+	for i := startIndex; i < endIndex; i += 2 {
+		key := vm.stack.items[i].(*data.String)
+		exports[key.Value] = vm.stack.items[i+1]
+	}
+	return &data.Module{Name: name.(*data.String).Value, Exports: exports}
+}
+
+func (vm *VM) executeModuleIndex(module, index data.Data) error {
+	mod := module.(*data.Module)
+	name := index.(*data.String).Value
+
+	value, ok := mod.Export(name)
+	if !ok {
+		return fmt.Errorf("module %s has no export %s", mod.Name, name)
+	}
+	return vm.stack.push(value)
+}
@@ -2,6 +2,7 @@ package vm
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/ape-lang/ape/src/compiler/operation"
 	"github.com/ape-lang/ape/src/data"
@@ -14,9 +15,15 @@ func (vm *VM) executeBinaryOp(op operation.Opcode) error {
 	leftType := left.Type()
 	rightType := right.Type()
 
-	switch {
-	case leftType == data.INTEGER_TYPE && rightType == data.INTEGER_TYPE:
+	if leftType == data.INTEGER_TYPE && rightType == data.INTEGER_TYPE {
 		return vm.executeBinaryIntegerOp(op, left, right)
+	}
+
+	_, leftIsNumber := data.NumberValue(left)
+	_, rightIsNumber := data.NumberValue(right)
+	switch {
+	case leftIsNumber && rightIsNumber:
+		return vm.executeBinaryFloatOp(op, left, right)
 
 	case leftType == data.STRING_TYPE && rightType == data.STRING_TYPE:
 		return vm.executeBinaryStringOp(op, left, right)
@@ -43,14 +50,68 @@ func (vm *VM) executeBinaryIntegerOp(op operation.Opcode, left, right data.Data)
 		result = leftVal * rightVal
 
 	case operation.Div:
+		if rightVal == 0 {
+			return fmt.Errorf("division by zero")
+		}
 		result = leftVal / rightVal
 
+	case operation.Mod:
+		if rightVal == 0 {
+			return fmt.Errorf("division by zero")
+		}
+		result = leftVal % rightVal
+
+	case operation.BitAnd:
+		result = leftVal & rightVal
+
+	case operation.BitOr:
+		result = leftVal | rightVal
+
+	case operation.BitXor:
+		result = leftVal ^ rightVal
+
+	case operation.ShiftLeft:
+		if rightVal < 0 {
+			return fmt.Errorf("negative shift count: %d", rightVal)
+		}
+		result = leftVal << uint64(rightVal)
+
+	case operation.ShiftRight:
+		if rightVal < 0 {
+			return fmt.Errorf("negative shift count: %d", rightVal)
+		}
+		result = leftVal >> uint64(rightVal)
+
 	default:
 		return fmt.Errorf("unknown integer operator: %d", op)
 	}
 	return vm.stack.push(&data.Integer{Value: result})
 }
 
+func (vm *VM) executeBinaryFloatOp(op operation.Opcode, left, right data.Data) error {
+	var result float64
+
+	leftVal, _ := data.NumberValue(left)
+	rightVal, _ := data.NumberValue(right)
+
+	switch op {
+	case operation.Add:
+		result = leftVal + rightVal
+	case operation.Sub:
+		result = leftVal - rightVal
+	case operation.Mul:
+		result = leftVal * rightVal
+	case operation.Div:
+		result = leftVal / rightVal
+	case operation.Mod:
+		result = math.Mod(leftVal, rightVal)
+	default:
+		return fmt.Errorf("unknown float operator: %d", op)
+	}
+
+	return vm.stack.push(&data.Float{Value: result})
+}
+
 func (vm *VM) executeBinaryStringOp(op operation.Opcode, left, right data.Data) error {
 	var result string
 
@@ -0,0 +1,17 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+func (vm *VM) executeBitNotOp() error {
+	operand := vm.stack.pop()
+
+	integer, ok := operand.(*data.Integer)
+	if !ok {
+		return fmt.Errorf("unsupported type for bitwise not: %s", operand.Type())
+	}
+	return vm.stack.push(&data.Integer{Value: ^integer.Value})
+}
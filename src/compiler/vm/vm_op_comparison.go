@@ -11,40 +11,16 @@ func (vm *VM) executeComparison(op operation.Opcode) error {
 	right := vm.stack.pop()
 	left := vm.stack.pop()
 
-	if left.Type() == data.INTEGER_TYPE || right.Type() == data.INTEGER_TYPE {
-		return vm.executeIntegerComparison(op, left, right)
-	}
-
-	switch op {
-	case operation.Equal:
-		return vm.executeBoolean(right == left)
-
-	case operation.NotEqual:
-		return vm.executeBoolean(right != left)
-
-	default:
-		return fmt.Errorf("unknown operator: %d (%s %s)",
-			op, left.Type(), right.Type())
+	operator, ok := operation.ComparisonOperator(op)
+	if !ok {
+		return fmt.Errorf("unknown operator: %d", op)
 	}
-}
 
-func (vm *VM) executeIntegerComparison(op operation.Opcode, left, right data.Data) error {
-	leftValue := left.(*data.Integer).Value
-	rightValue := right.(*data.Integer).Value
-
-	switch op {
-	case operation.Equal:
-		return vm.executeBoolean(rightValue == leftValue)
-
-	case operation.NotEqual:
-		return vm.executeBoolean(rightValue != leftValue)
-
-	case operation.GreaterThan:
-		return vm.executeBoolean(leftValue > rightValue)
-
-	default:
-		return fmt.Errorf("unknown operator: %d", op)
+	result, ok := data.Comparison(operator, left, right)
+	if !ok {
+		return fmt.Errorf("unsupported types for comparison: %s %s %s", left.Type(), operator, right.Type())
 	}
+	return vm.executeBoolean(result)
 }
 
 func (vm *VM) executeBoolean(val bool) error {
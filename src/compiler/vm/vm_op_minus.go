@@ -9,10 +9,14 @@ import (
 func (vm *VM) executeMinusOp() error {
 	operand := vm.stack.pop()
 
-	if operand.Type() != data.INTEGER_TYPE {
+	switch operand := operand.(type) {
+	case *data.Integer:
+		return vm.stack.push(&data.Integer{Value: -operand.Value})
+
+	case *data.Float:
+		return vm.stack.push(&data.Float{Value: -operand.Value})
+
+	default:
 		return fmt.Errorf("unsupported type for negation: %s", operand.Type())
 	}
-
-	value := operand.(*data.Integer).Value
-	return vm.stack.push(&data.Integer{Value: -value})
 }
@@ -1,14 +1,24 @@
 package vm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ape-lang/ape/src/ast"
 	"github.com/ape-lang/ape/src/compiler/compiler"
+	"github.com/ape-lang/ape/src/compiler/operation"
 	"github.com/ape-lang/ape/src/data"
 	"github.com/ape-lang/ape/src/lexer"
+	"github.com/ape-lang/ape/src/module"
 	"github.com/ape-lang/ape/src/parser"
+	"github.com/ape-lang/ape/src/token"
 )
 
 func parse(input string) *ast.Program {
@@ -31,6 +41,19 @@ func testIntegerData(expected int64, actual data.Data) error {
 	return nil
 }
 
+func testFloatData(expected float64, actual data.Data) error {
+	result, ok := actual.(*data.Float)
+
+	if !ok {
+		return fmt.Errorf("data is not Float. got=%T (%+v)", actual, actual)
+	}
+
+	if result.Value != expected {
+		return fmt.Errorf("data has wrong value. got=%g, want=%g", result.Value, expected)
+	}
+	return nil
+}
+
 func testBooleanData(expected bool, actual data.Data) error {
 	result, ok := actual.(*data.Boolean)
 	if !ok {
@@ -65,23 +88,34 @@ type vmTestCase struct {
 func runVMTests(t *testing.T, tests []vmTestCase) {
 	t.Helper()
 	for _, tt := range tests {
-		program := parse(tt.input)
-		comp := compiler.New()
-		err := comp.Compile(program)
+		// The optimizations must not change the results
+		for _, level := range []int{compiler.O0, compiler.O1} {
+			program := parse(tt.input)
+			comp := compiler.New()
+			comp.SetOptimization(level)
+			err := comp.Compile(program)
 
-		if err != nil {
-			t.Fatalf("compiler error: %s", err)
-		}
+			if err != nil {
+				t.Fatalf("compiler error: %s", err)
+			}
 
-		vm := New(comp.Bytecode())
-		err = vm.Run()
+			vm := New(comp.Bytecode())
+			err = vm.Run()
 
-		if err != nil {
-			t.Fatalf("vm error: %s", err)
-		}
+			// Errors of the builtins are raised, the expected error is the one not caught
+			if expected, ok := tt.expected.(*data.Error); ok {
+				if err == nil || err.Error() != expected.Message {
+					t.Errorf("wrong error (O%d). expected=%q, got=%v", level, expected.Message, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("vm error (O%d): %s", level, err)
+			}
 
-		stackElem := vm.stack.popped()
-		testExpectedData(t, tt.expected, stackElem)
+			stackElem := vm.stack.popped()
+			testExpectedData(t, tt.expected, stackElem)
+		}
 	}
 
 }
@@ -97,6 +131,12 @@ func testExpectedData(t *testing.T, expected interface{}, actual data.Data) {
 			t.Errorf("testIntegerData failed: %s", err)
 		}
 
+	case float64:
+		err := testFloatData(expected, actual)
+		if err != nil {
+			t.Errorf("testFloatData failed: %s", err)
+		}
+
 	case bool:
 		err := testBooleanData(bool(expected), actual)
 		if err != nil {
@@ -120,12 +160,12 @@ func testExpectedData(t *testing.T, expected interface{}, actual data.Data) {
 			t.Errorf("data not Array: %T (%+v)", actual, actual)
 			return
 		}
-		if len(array.Elements) != len(expected) {
-			t.Errorf("wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+		if array.Len() != len(expected) {
+			t.Errorf("wrong num of elements. want=%d, got=%d", len(expected), array.Len())
 			return
 		}
 		for i, expectedElem := range expected {
-			err := testIntegerData(int64(expectedElem), array.Elements[i])
+			err := testIntegerData(int64(expectedElem), array.Get(i))
 			if err != nil {
 				t.Errorf("testIntegerData failed: %s", err)
 			}
@@ -138,12 +178,12 @@ func testExpectedData(t *testing.T, expected interface{}, actual data.Data) {
 			return
 		}
 
-		if len(hash.Pairs) != len(expected) {
-			t.Errorf("hash has wrong number of Pairs. want=%d, got=%d", len(expected), len(hash.Pairs))
+		if hash.Len() != len(expected) {
+			t.Errorf("hash has wrong number of Pairs. want=%d, got=%d", len(expected), hash.Len())
 			return
 		}
 		for expectedKey, expectedValue := range expected {
-			pair, ok := hash.Pairs[expectedKey]
+			pair, ok := hash.Get(expectedKey)
 			if !ok {
 				t.Errorf("no pair for given key in Pairs")
 			}
@@ -154,15 +194,6 @@ func testExpectedData(t *testing.T, expected interface{}, actual data.Data) {
 			}
 		}
 
-	case *data.Error:
-		err, ok := actual.(*data.Error)
-		if !ok {
-			t.Errorf("object is not Error: %T (%+v)", actual, actual)
-			return
-		}
-		if err.Message != expected.Message {
-			t.Errorf("wrong error message. expected=%q, got=%q", expected.Message, err.Message)
-		}
 	}
 }
 
@@ -185,11 +216,51 @@ func TestIntegerArithmetic(t *testing.T) {
 		{"-10", -10},
 		{"-50 + 100 + -50", 0},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"1 + 10 % 4", 3},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"~5", -6},
+		{"~-1", 0},
 	}
 
 	runVMTests(t, tests)
 }
 
+func TestFloatArithmetic(t *testing.T) {
+	tests := []vmTestCase{
+		{"3.14", 3.14},
+		{"1e-9", 1e-9},
+		{"1.5 + 2.25", 3.75},
+		{"1 + 0.5", 1.5},
+		{"0.5 + 1", 1.5},
+		{"5 / 2.0", 2.5},
+		{"2.5 * 4", 10.0},
+		{"1 - 1.5", -0.5},
+		{"-2.5", -2.5},
+		{"-(1.5 * 2)", -3.0},
+		{"1.5 > 1", true},
+		{"1 < 1.5", true},
+		{"1 == 1.0", true},
+		{"1.0 != 1", false},
+		{"0.1 + 0.2 == 0.3", false},
+		{"5.5 % 2", 1.5},
+		{"1.5 >= 1.5", true},
+		{"1 <= 0.5", false},
+		{`{1.5: "a", 2: "b"}[1.5]`, "a"},
+		{`{-0.0: "zero"}[0.0]`, "zero"},
+		{`{1: "a"}[1.0]`, "a"},
+		{`{2.0: "b"}[2]`, "b"},
+		{`len({1: "a", 1.0: "b"})`, 1},
+		{`{1: "x"} == {1.0: "x"}`, true},
+	}
+	runVMTests(t, tests)
+}
+
 func TestBooleanExpressions(t *testing.T) {
 	tests := []vmTestCase{
 		{"true", true},
@@ -218,11 +289,110 @@ func TestBooleanExpressions(t *testing.T) {
 		{"!!false", false},
 		{"!!5", true},
 		{"!(if (false) { 5; })", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"3 >= 2", true},
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"1 && 2", true},
+		{"true || false", true},
+		{"false || false", false},
+		{"false || 0", true},
+		{"!true || 1 > 2", false},
+		{"1 < 2 && 2 < 3", true},
+		{"(if (false) { 1 }) || false", false},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestComparisons(t *testing.T) {
+	tests := []vmTestCase{
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] == [1, 2, 3]", false},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`"ape" == "ape"`, true},
+		{`"ape" != "apes"`, true},
+		{"1 == 1.0", true},
+		{`1 == "1"`, false},
+		{`1 != "1"`, true},
+		{"[1] == 1", false},
+		{"let n = if (false) { 1 }; n == n", true},
+		{"(if (false) { 1 }) == false", false},
+		{"let f = fn() {}; f == f", true},
+		{"fn() {} == fn() {}", false},
+		{"let x = 0.0 / 0.0; x == x", false},
+		{"let x = 0.0 / 0.0; x < 1 || x >= 1", false},
+		{`"a" < "b"`, true},
+		{`"ab" < "a"`, false},
+		{`"a" <= "a"`, true},
+		{`"b" > "abc"`, true},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] < [1, 2, 0]", true},
+		{"[2] > [1, 9]", true},
+		{"[1, 2] >= [1, 2]", true},
+		{`[1, "b"] < [1, "a"]`, false},
+		{"[1, 2.5] < [1, 3]", true},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a == b", true},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a < b", false},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a <= b", true},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestShortCircuitEvaluation(t *testing.T) {
+	tests := []vmTestCase{
+		{"let x = [1]; false && x[5 / 0]", false},
+		{"let x = [1]; true || x[5 / 0]", true},
+		{"let f = fn() { 5 / 0 }; false && f()", false},
+		{"let f = fn() { 5 / 0 }; true || f()", true},
+		{"let f = fn() { true }; false || f()", true},
 	}
 
 	runVMTests(t, tests)
 }
 
+func TestOperatorErrors(t *testing.T) {
+	tests := []vmTestCase{
+		{"5 / 0", "division by zero"},
+		{"5 % 0", "division by zero"},
+		{"1 << -1", "negative shift count: -1"},
+		{"~true", "unsupported type for bitwise not: BOOLEAN"},
+		{`1 < "a"`, "unsupported types for comparison: INTEGER < STRING"},
+		{"true > false", "unsupported types for comparison: BOOLEAN > BOOLEAN"},
+		{`[1] < ["a"]`, "unsupported types for comparison: ARRAY < ARRAY"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		comp := compiler.New()
+		err := comp.Compile(program)
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err == nil {
+			t.Fatalf("expected VM error but resulted in none.")
+		}
+
+		if err.Error() != tt.expected {
+			t.Fatalf("wrong VM error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
 func TestConditionals(t *testing.T) {
 	tests := []vmTestCase{
 		{"if (true) { 10 }", 10},
@@ -240,6 +410,534 @@ func TestConditionals(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestWhileLoops(t *testing.T) {
+	tests := []vmTestCase{
+		{"while (false) { 1 }; 5", 5},
+		{"while (true) { break; }; 5", 5},
+		{"let f = fn() { while (true) { return 10; } }; f()", 10},
+		{"let f = fn() { while (false) { 1 } }; f()", data.NULL},
+		{"if (true) { while (false) {} }", data.NULL},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestForLoops(t *testing.T) {
+	tests := []vmTestCase{
+		{"let last = 0; for (let x in [1, 2, 3]) { let last = x; }; last", 3},
+		{"let last = 0; for (let x in range(10)) { if (x == 3) { break; } let last = x; }; last", 2},
+		{"let last = 0; for (let x in range(5)) { if (x % 2 == 0) { continue; } let last = x; }; last", 3},
+		{"let last = 0; for (let x in range(10, 0, -3)) { let last = x; }; last", 1},
+		{"let f = fn() { for (let x in range(0)) { return 1; } 0 }; f()", 0},
+		{`let last = ""; for (let c in "abñ") { let last = c; }; last`, "ñ"},
+		{`let last = ""; for (let k in {"b": 2, "a": 1}) { let last = k; }; last`, "a"},
+		{`let keys = ""; for (let k in {"c": 1, "a": 2, "b": 3}) { keys = keys + k; }; keys`, "cab"},
+		{"let f = fn(xs) { for (let x in xs) { if (x > 1) { return x; } } }; f([1, 2, 3])", 2},
+		{"let f = fn(xs) { for (let x in xs) { x } }; f([1, 2, 3])", data.NULL},
+		{"let f = fn() { for (let x in [1, 2]) { for (let y in [3, 4]) { return [x, y]; } } }; f()", []int{1, 3}},
+		{"for (let x in [1, 2]) { for (let y in [3, 4]) { break; } }; 5", 5},
+		{"for (let x in range(3000)) { for (let y in [1]) { break; } }; 5", 5},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestIteratingNonIterables(t *testing.T) {
+	program := parse("for (let x in 5) { x }")
+	comp := compiler.New()
+	err := comp.Compile(program)
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	vm := New(comp.Bytecode())
+	err = vm.Run()
+	if err == nil {
+		t.Fatalf("expected VM error but resulted in none.")
+	}
+
+	expected := "not iterable: INTEGER"
+	if err.Error() != expected {
+		t.Fatalf("wrong VM error: want=%q, got=%q", expected, err)
+	}
+}
+
+func TestTryStatements(t *testing.T) {
+	tests := []vmTestCase{
+		{`let r = ""; try { throw("boom") } catch (e) { r = e["message"] }; r`, "boom"},
+		{`let r = ""; try { 1 / 0 } catch (e) { r = e["kind"] }; r`, "RuntimeError"},
+		{`let r = ""; try { throw("boom") } catch (e) { r = e["kind"] }; r`, "Error"},
+		{`let r = 0; try { throw(5) } catch (e) { r = e["value"] }; r`, 5},
+		{`let r = 0; try { r = 1 } finally { r = r * 10 + 2 }; r`, 12},
+		{`let r = 0; try { throw("x") } catch (e) { r = 1 } finally { r = r * 10 + 2 }; r`, 12},
+		{`let r = 0; try { r = 1 + [1, 2, throw("x")][0] } catch (e) { r = 2 }; r + 1`, 3},
+		{`let f = fn() { throw("deep") }; let g = fn() { f() + 1 }; let r = ""; try { g() } catch (e) { r = e["message"] }; r`, "deep"},
+		{`let f = fn(n) { if (n == 0) { throw("bottom") }; f(n - 1) }; let r = ""; try { f(50) } catch (e) { r = e["message"] }; r`, "bottom"},
+		{`let r = 0; let f = fn() { try { return 1 } finally { r = 2 } }; f() + r`, 3},
+		{`let f = fn() { try { throw("a") } finally { return 5 } }; f()`, 5},
+		{`let f = fn() { try { throw("a") } catch (e) { return 6 } }; f()`, 6},
+		{`let r = 0; for (let x in range(5)) { try { if (x == 2) { break } } finally { r += 1 } }; r`, 3},
+		{`let r = 0; for (let x in range(4)) { try { continue } finally { r += x } }; r`, 6},
+		{`let r = ""; try { try { throw("inner") } finally { r = "f" } } catch (e) { r = r + e["message"] }; r`, "finner"},
+		{`let r = ""; try { try { throw("a") } catch (e) { throw(e) } } catch (e) { r = e["message"] }; r`, "a"},
+		{`let f = fn() { throw("x") }; let r = ""; try { f() } catch (e) { r = e["trace"][0] + ", " + e["trace"][1] }; r`, "at f (1:21), at <main> (1:49)"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []vmTestCase{
+		{`let count = fn(n, acc) { if (n == 0) { acc } else { count(n - 1, acc + 1) } }; count(100000, 0)`, 100000},
+		{`let sum = fn(n, acc) { if (n == 0) { return acc; } return sum(n - 1, acc + n); }; sum(100000, 0)`, 5000050000},
+		{`let odd = 0; let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } }; odd = fn(n) { if (n == 0) { false } else { even(n - 1) } }; even(100001)`, false},
+		{`let make = fn(step) { let loop = fn(n, acc) { if (n == 0) { acc } else { loop(n - 1, acc + step) } }; loop }; make(2)(50000, 0)`, 100000},
+		{`let f = fn(a) { len(a) }; f([1, 2, 3])`, 3},
+		{`let g = fn() { throw("x") }; let f = fn() { try { return g(); } catch (e) { return 7; } }; f()`, 7},
+		// The frame of a function is replaced by the function it calls in tail position
+		{`let f = fn() { throw("x") }; let g = fn() { f() }; let r = ""; try { g() } catch (e) { r = e["trace"][0] + ", " + e["trace"][1] }; r`, "at f (1:21), at <main> (1:71)"},
+	}
+
+	runVMTests(t, tests)
+}
+
+// writes the given modules (by path relative to the directory) into a new temporary directory, added to APE_PATH
+func writeModules(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, source := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(module.PathVariable, dir)
+}
+
+func TestImports(t *testing.T) {
+	writeModules(t, map[string]string{
+		"math.ape":         "export let square = fn(x) { x * x }; let hidden = 1; export let twice = fn(x) { x + x };",
+		"counter.ape":      "let count = 0; export let next = fn() { count += 1; count };",
+		"uses_counter.ape": `import "counter"; export let first = counter.next();`,
+		"fib.ape":          "export let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };",
+		"lib/reduce.ape":   "export let reduce = fn(xs, f, acc) { for (let x in xs) { acc = f(acc, x) }; acc };",
+		"lib/sum.ape":      `import { reduce } from "./reduce"; export let sum = fn(xs) { reduce(xs, fn(a, b) { a + b }, 0) };`,
+	})
+
+	tests := []vmTestCase{
+		{`import "math"; math.square(3) + math.twice(2)`, 13},
+		{`import { square } from "math"; square(4)`, 16},
+		{`import "lib/sum"; sum.sum([1, 2, 3])`, 6},
+		{`import { fib } from "fib"; fib(10)`, 55},
+		{`import "uses_counter"; import "counter"; counter.next()`, 2},
+		{`import "math"; let hidden = 5; hidden`, 5},
+		{`let f = fn() { 1 }; import "math"; f() + math.square(2)`, 5},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestImportErrors(t *testing.T) {
+	writeModules(t, map[string]string{"math.ape": "export let one = 1; let hidden = 2;"})
+
+	tests := []vmTestCase{
+		{`import "math"; math.hidden`, "module math has no export hidden"},
+		{`import { one, hidden } from "math"`, "module math has no export hidden"},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err == nil {
+			t.Fatalf("expected VM error but resulted in none.")
+		}
+
+		if err.Error() != tt.expected {
+			t.Fatalf("wrong VM error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []vmTestCase{
+		{"let x = 1; x = 2; x", 2},
+		{"let x = 1; x = x + 1", 2},
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x %= 4", 2},
+		{"let a = 1; let b = 2; a = b = 3; a + b", 6},
+		{"let f = fn() { let x = 1; x += 1; x }; f()", 2},
+		{"let f = fn(x) { x = x * 2; x }; f(4)", 8},
+		{"let i = 0; let sum = 0; while (i < 5) { i += 1; sum += i; }; sum", 15},
+		{"let sum = 0; for (let x in range(1, 4)) { sum += x }; sum", 6},
+		{"let xs = [1, 2, 3]; xs[1] = 5; xs", []int{1, 5, 3}},
+		{"let xs = [1, 2, 3]; xs[2] += 10; xs[2]", 13},
+		{"let xs = [1, 2]; let ys = xs; ys[0] = 9; xs[0]", 9},
+		{`let h = {"a": 1}; h["a"] += 1; h["b"] = 5; h["a"] + h["b"]`, 7},
+		{"let xs = [[1], [2]]; xs[1][0] = 7; xs[1][0]", 7},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestClosureAssignments(t *testing.T) {
+	tests := []vmTestCase{
+		{
+			input: `
+			let counter = fn() {
+				let count = 0;
+				fn() { count += 1; count }
+			};
+			let c = counter();
+			c(); c(); c()
+			`,
+			expected: 3,
+		},
+		{
+			input: `
+			let pair = fn() {
+				let value = 0;
+				let set = fn(v) { value = v };
+				let get = fn() { value };
+				[set, get]
+			};
+			let p = pair();
+			p[0](42);
+			p[1]()
+			`,
+			expected: 42,
+		},
+		{
+			input: `
+			let outer = fn() {
+				let x = 1;
+				let middle = fn() { fn() { x = x * 10 } };
+				middle()();
+				x
+			};
+			outer()
+			`,
+			expected: 10,
+		},
+		{
+			input: `
+			let make = fn() { let n = 0; fn() { n += 1 } };
+			let a = make();
+			let b = make();
+			a(); a(); b()
+			`,
+			expected: 1,
+		},
+		{
+			input: `
+			let f = fn() {
+				let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+				fib(10)
+			};
+			f()
+			`,
+			expected: 55,
+		},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestAssignmentErrors(t *testing.T) {
+	tests := []vmTestCase{
+		{"let xs = [1]; xs[1] = 2", "array index out of range: 1"},
+		{`let xs = [1]; xs["a"] = 2`, "array index must be INTEGER, got STRING"},
+		{`let s = "a"; s[0] = "b"`, "index assignment not supported: STRING"},
+		{`let h = {}; h[fn() {}] = 1`, "unusable as hash key: CLOSURE_TYPE"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		comp := compiler.New()
+		err := comp.Compile(program)
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err == nil {
+			t.Fatalf("expected VM error but resulted in none.")
+		}
+
+		if err.Error() != tt.expected {
+			t.Fatalf("wrong VM error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestUncaughtExceptions(t *testing.T) {
+	tests := []vmTestCase{
+		{`throw("boom")`, "boom"},
+		{`try { throw("boom") } finally { 1 }`, "boom"},
+		{`try { throw("a") } catch (e) { throw("b") }`, "b"},
+		{`try { 1 } catch (e) { 1 }; 1 / 0`, "division by zero"},
+		{`let f = fn() { try { 1 } catch (e) { 1 } }; f(); throw([1])`, "[1]"},
+	}
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+		comp := compiler.New()
+		err := comp.Compile(program)
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err == nil {
+			t.Fatalf("expected VM error but resulted in none.")
+		}
+
+		if err.Error() != tt.expected {
+			t.Fatalf("wrong VM error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestRuntimeErrorStackTrace(t *testing.T) {
+	input := `let divide = fn(a, b) {
+	a / b
+};
+let compute = fn(x) { 1 + fn() { 1 + divide(x, 0) }() };
+compute(10);`
+
+	// The calls are not in tail position, so each one keeps its frame
+	expected := []data.TraceFrame{
+		{Function: "divide", Position: token.Position{File: "main.ape", Line: 2, Column: 4}},
+		{Function: "<anonymous>", Position: token.Position{File: "main.ape", Line: 4, Column: 44}},
+		{Function: "compute", Position: token.Position{File: "main.ape", Line: 4, Column: 52}},
+		{Function: "<main>", Position: token.Position{File: "main.ape", Line: 5, Column: 8}},
+	}
+
+	l := lexer.NewWithFile(input, "main.ape")
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	comp := compiler.New()
+	err := comp.Compile(program)
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	vm := New(comp.Bytecode())
+	err = vm.Run()
+
+	runtimeErr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("expected *RuntimeError, got %T (%+v)", err, err)
+	}
+
+	if runtimeErr.Message != "division by zero" {
+		t.Errorf("wrong error message: want=%q, got=%q", "division by zero", runtimeErr.Message)
+	}
+
+	if len(runtimeErr.Trace) != len(expected) {
+		t.Fatalf("wrong trace length: want=%d, got=%d\n%s", len(expected), len(runtimeErr.Trace), runtimeErr.StackTrace())
+	}
+
+	for i, frame := range expected {
+		if runtimeErr.Trace[i] != frame {
+			t.Errorf("wrong trace frame %d: want=%+v, got=%+v", i, frame, runtimeErr.Trace[i])
+		}
+	}
+
+	expectedTrace := `at divide (main.ape:2:4)
+at <anonymous> (main.ape:4:44)
+at compute (main.ape:4:52)
+at <main> (main.ape:5:8)`
+
+	if runtimeErr.StackTrace() != expectedTrace {
+		t.Errorf("wrong stack trace: want=%q, got=%q", expectedTrace, runtimeErr.StackTrace())
+	}
+}
+
+func TestGrowingStack(t *testing.T) {
+	// Deeper than the initial stack, with more globals than the initial ones
+	globals := strings.Builder{}
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&globals, "let g%c%c = %d; ", 'a'+i/26, 'a'+i%26, i)
+	}
+
+	tests := []vmTestCase{
+		{`let sum = fn(n) { if (n == 0) { 0 } else { n + sum(n - 1) } }; sum(10000)`, 50005000},
+		{`let f = fn() { 1 + f() }; let r = ""; try { f() } catch (e) { r = e.message }; r`, "stack overflow"},
+		{globals.String() + "gaa + gln", 299},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestStackOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		config   Config
+		expected string
+	}{
+		{
+			`let f = fn(n) { 1 + f(n + 1) };
+f(0);`,
+			Config{InitialStack: 16, MaxStack: 4096, MaxFrames: 100},
+			"at f (1:22) [repeated 98 more times]\nat <main> (2:2)",
+		},
+		{
+			`let f = fn(n) { let a = 1; let b = 2; 1 + f(n + 1) };
+f(0);`,
+			Config{InitialStack: 16, MaxStack: 64, MaxFrames: 100},
+			"at f (1:25)\nat f (1:44) [repeated 11 more times]\nat <main> (2:2)",
+		},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := NewWithConfig(comp.Bytecode(), tt.config)
+		err = vm.Run()
+
+		runtimeErr, ok := err.(*RuntimeError)
+		if !ok {
+			t.Fatalf("expected *RuntimeError, got %T (%+v)", err, err)
+		}
+		if runtimeErr.Message != "stack overflow" {
+			t.Errorf("wrong error message: want=%q, got=%q", "stack overflow", runtimeErr.Message)
+		}
+		if runtimeErr.StackTrace() != tt.expected {
+			t.Errorf("wrong stack trace: want=%q, got=%q", tt.expected, runtimeErr.StackTrace())
+		}
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	tests := []Config{
+		{},
+		{MaxFrames: 100},
+		{InitialStack: 4},
+		{MaxStack: -1, Limits: data.Limits{Steps: 1000}},
+	}
+
+	for _, config := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse("let add = fn(a, b) { a + b }; add(1, 2)"))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := NewWithConfig(comp.Bytecode(), config)
+		if err := vm.Run(); err != nil {
+			t.Fatalf("vm error with %+v: %s", config, err)
+		}
+		testExpectedData(t, 3, vm.Result())
+	}
+}
+
+func TestCall(t *testing.T) {
+	comp := compiler.New()
+	err := comp.Compile(parse(`let add = fn(a, b) { a + b }; let fail = fn() { try { throw("x") } catch (e) { throw("boom") } };`))
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	vm := New(comp.Bytecode())
+	err = vm.Run()
+	if err != nil {
+		t.Fatalf("vm error: %s", err)
+	}
+
+	result, err := vm.Call(vm.Globals()[0], &data.Integer{Value: 1}, &data.Integer{Value: 2})
+	if err != nil {
+		t.Fatalf("call failed: %s", err)
+	}
+	if err := testIntegerData(3, result); err != nil {
+		t.Errorf("wrong result: %s", err)
+	}
+
+	result, err = vm.Call(data.GetBuiltinDef("len"), &data.String{Value: "abc"})
+	if err != nil {
+		t.Fatalf("call failed: %s", err)
+	}
+	if err := testIntegerData(3, result); err != nil {
+		t.Errorf("wrong result: %s", err)
+	}
+
+	_, err = vm.Call(vm.Globals()[1])
+	runtimeErr, ok := err.(*RuntimeError)
+	if !ok || runtimeErr.Message != "boom" {
+		t.Fatalf("expected a *RuntimeError, got %T (%v)", err, err)
+	}
+	if runtimeErr.StackTrace() != "at fail (1:85)\nat <native>\nat <main> (1:31)" {
+		t.Errorf("wrong stack trace: %q", runtimeErr.StackTrace())
+	}
+
+	// The VM is left as it was, so it can still be called
+	if vm.stack.pointer != 0 || vm.frames.index != 1 || len(vm.handlers) != 0 {
+		t.Errorf("the call was not unwound: stack=%d, frames=%d, handlers=%d", vm.stack.pointer, vm.frames.index, len(vm.handlers))
+	}
+}
+
+func TestLimits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+
+	tests := []struct {
+		input    string
+		ctx      context.Context
+		limits   data.Limits
+		expected error // the type of error expected (nil when the program completes)
+	}{
+		{"let x = 1 + 2; x * 3", context.Background(), data.Limits{Steps: 100, Allocations: 10}, nil},
+		{"while (true) {}", context.Background(), data.Limits{Steps: 10000}, &data.StepLimitError{}},
+		{"let f = fn(n) { f(n + 1) }; f(0)", context.Background(), data.Limits{Steps: 10000}, &data.StepLimitError{}},
+		{"try { while (true) {} } catch (e) { 1 } finally { 2 }", context.Background(), data.Limits{Steps: 1000}, &data.StepLimitError{}},
+		{"let a = []; while (true) { a = push(a, 1); }", context.Background(), data.Limits{Allocations: 100000}, &data.AllocationLimitError{}},
+		{"let a = []; let i = 0; while (i < 100000) { a = push(a, i); i += 1; }", context.Background(), data.Limits{Allocations: 1000000}, nil},
+		{`let s = ""; while (true) { s = s + "abc"; }`, context.Background(), data.Limits{Allocations: 100000}, &data.AllocationLimitError{}},
+		{`try { repeat("abc", 500000000) } catch (e) { 1 }`, context.Background(), data.Limits{Allocations: 100000}, &data.AllocationLimitError{}},
+		{`repeat("abc", 1000)`, context.Background(), data.Limits{Allocations: 100000}, nil},
+		{"1 + 2", canceled, data.Limits{}, &data.CanceledError{}},
+		{"while (true) {}", timeout, data.Limits{}, &data.CanceledError{}},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		config := DefaultConfig
+		config.Limits = tt.limits
+		err = NewWithConfig(comp.Bytecode(), config).RunContext(tt.ctx)
+
+		if fmt.Sprintf("%T", err) != fmt.Sprintf("%T", tt.expected) {
+			t.Errorf("wrong error for %q: want=%T, got=%T (%v)", tt.input, tt.expected, err, err)
+		}
+	}
+
+	err := New(compiler.New().Bytecode()).RunContext(canceled)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the error to wrap context.Canceled, got %v", err)
+	}
+}
+
 func TestGlobalLetStatements(t *testing.T) {
 	tests := []vmTestCase{
 		{"let one = 1; one", 1},
@@ -563,6 +1261,11 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("héllo wörld")`, 11},
+		{`byte_len("héllo wörld")`, 13},
+		{`byte_len(1)`,
+			&data.Error{Message: "argument to 'byte_len' must be STRING, got INTEGER"},
+		},
 		{
 			`len(1)`,
 			&data.Error{Message: "argument to 'len' not supported, got INTEGER"},
@@ -593,6 +1296,105 @@ func TestBuiltinFunctions(t *testing.T) {
 	runVMTests(t, tests)
 }
 
+func TestStrings(t *testing.T) {
+	tests := []vmTestCase{
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[5]`, data.NULL},
+		{`"héllo"[-1]`, data.NULL},
+		{`"héllo wörld"[1:4]`, "éll"},
+		{`"héllo"[:2]`, "hé"},
+		{`"héllo"[3:]`, "lo"},
+		{`"héllo"[:]`, "héllo"},
+		{`"abc"[-5:10]`, "abc"},
+		{`"abc"[2:1]`, ""},
+		{`[1, 2, 3, 4][1:3]`, []int{2, 3}},
+		{`let a = [1, 2, 3]; let b = a[1:]; b[0] = 5; a`, []int{1, 2, 3}},
+		{`let r = ""; try { "abc"["a":] } catch (e) { r = e.message }; r`, "slice bounds must be INTEGER, got STRING"},
+		{`let r = ""; try { 1[0:1] } catch (e) { r = e.message }; r`, "slice operator not supported: INTEGER"},
+		{`split("a,b,,c", ",") == ["a", "b", "", "c"]`, true},
+		{`split("hé", "") == ["h", "é"]`, true},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`join([], "-")`, ""},
+		{`trim("  ape \n")`, "ape"},
+		{`upper("héllo")`, "HÉLLO"},
+		{`lower("HÉLLO")`, "héllo"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`contains("héllo", "él")`, true},
+		{`contains("héllo", "x")`, false},
+		{`starts_with("héllo", "hé")`, true},
+		{`ends_with("héllo", "lo")`, true},
+		{`index_of("héllo", "l")`, 2},
+		{`index_of("héllo", "x")`, -1},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`chars("hé!") == ["h", "é", "!"]`, true},
+		{`let s = "héllo"; let r = ""; for (let i in range(len(s))) { r = s[i] + r }; r`, "olléh"},
+		{`join(["a", 1], "")`, &data.Error{Message: "elements joined by 'join' must be STRING, got INTEGER"}},
+		{`upper(1)`, &data.Error{Message: "argument to 'upper' must be STRING, got INTEGER"}},
+		{`replace("a", "b")`, &data.Error{Message: "wrong number of arguments. got=2, want=3"}},
+		{`repeat("a", -1)`, &data.Error{Message: "'repeat' count must not be negative, got -1"}},
+	}
+	runVMTests(t, tests)
+}
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []vmTestCase{
+		{`len({"a": 1, "b": 2})`, 2},
+		{`len({})`, 0},
+		{`keys({"b": 1, "a": 2}) == ["b", "a"]`, true},
+		{`values({"b": 1, "a": 2})`, []int{1, 2}},
+		{`entries({"b": 1, 2: [3]}) == [["b", 1], [2, [3]]]`, true},
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`keys(delete({"a": 1, "b": 2, "c": 3}, "b")) == ["a", "c"]`, true},
+		{`delete({"a": 1}, "b") == {"a": 1}`, true},
+		{`let h = {"a": 1}; delete(h, "a"); len(h)`, 1},
+		{`merge({"a": 1, "b": 2}, {"c": 3, "a": 4}) == {"a": 4, "b": 2, "c": 3}`, true},
+		{`keys(merge({"a": 1, "b": 2}, {"c": 3, "a": 4})) == ["a", "b", "c"]`, true},
+		{`let h = {"a": 1}; merge(h, {"b": 2}); len(h)`, 1},
+		{`let h = {}; for (let e in entries({"x": 1, "y": 2})) { h = merge(h, {e[1]: e[0]}) }; h[2]`, "y"},
+		{`keys(1)`, &data.Error{Message: "argument to 'keys' must be HASH, got INTEGER"}},
+		{`has({}, [])`, &data.Error{Message: "unusable as hash key: ARRAY"}},
+		{`delete({})`, &data.Error{Message: "wrong number of arguments. got=1, want=2"}},
+		{`merge({}, [])`, &data.Error{Message: "arguments to 'merge' must be HASH, got ARRAY"}},
+		{`set({"a": 1}, "b", 2) == {"a": 1, "b": 2}`, true},
+		{`let h = {"a": 1}; set(h, "a", 2); h["a"]`, 1},
+		{`set([1, 2, 3], 1, 5)`, []int{1, 5, 3}},
+		{`let a = [1, 2]; let b = set(push(a, 3), 0, 4); a[0] = 5; [a, b] == [[5, 2], [4, 2, 3]]`, true},
+		{`set([1], 1, 2)`, &data.Error{Message: "array index out of range: 1"}},
+		{`set("a", 0, 1)`, &data.Error{Message: "argument to 'set' must be ARRAY or HASH, got STRING"}},
+	}
+	runVMTests(t, tests)
+}
+
+func TestHigherOrderBuiltins(t *testing.T) {
+	tests := []vmTestCase{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, []int{2, 4, 6}},
+		{`map(range(3), fn(x) { x + 1 })`, []int{1, 2, 3}},
+		{`map(["a", "bc"], len)`, []int{1, 2}},
+		{`filter([1, 2, 3, 4], fn(x) { x % 2 == 0 })`, []int{2, 4}},
+		{`reduce([1, 2, 3], fn(acc, x) { acc + x }, 10)`, 16},
+		{`reduce([1, 2, 3], fn(acc, x) { acc * x })`, 6},
+		{`reduce([], fn(acc, x) { acc + x })`, data.NULL},
+		{`let sum = 0; each([1, 2, 3], fn(x) { sum = sum + x }); sum`, 6},
+		{`sort_by([3, 1, 2], fn(x) { x })`, []int{1, 2, 3}},
+		{`map(sort_by([[2, 1], [1, 2], [1, 1]], fn(p) { p[0] }), fn(p) { p[1] })`, []int{2, 1, 1}},
+		{`find([1, 2, 3], fn(x) { x > 1 })`, 2},
+		{`find([1], fn(x) { false })`, data.NULL},
+		{`any([1, 2], fn(x) { x > 1 })`, true},
+		{`all([1, 2], fn(x) { x > 1 })`, false},
+		{`all([], fn(x) { false })`, true},
+		{`let calls = 0; any([1, 2, 3], fn(x) { calls = calls + 1; x == 2 }); calls`, 2},
+		{`map([1, 2], fn(x) { reduce(map([x, x], fn(y) { y * 10 }), fn(a, b) { a + b }) })`, []int{20, 40}},
+		{`let r = ""; try { map([1], fn(x) { throw("bad") }) } catch (e) { r = e.message }; r`, "bad"},
+		{`let r = ""; try { map([1], fn(a, b) { a }) } catch (e) { r = e.message }; r`, "wrong number of arguments: want=2, got=1"},
+		{`map(1, fn(x) { x })`, &data.Error{Message: "first argument to 'map' must be iterable, got INTEGER"}},
+		{`filter([1], 2)`, &data.Error{Message: "second argument to 'filter' must be a function, got INTEGER"}},
+		{`sort_by([1, "a"], fn(x) { x })`, &data.Error{Message: "keys of 'sort_by' must be all numbers or all strings, got INTEGER and STRING"}},
+	}
+	runVMTests(t, tests)
+}
+
 func TestClosures(t *testing.T) {
 	tests := []vmTestCase{
 		{
@@ -693,3 +1495,77 @@ func TestRecursiveFibonacci(t *testing.T) {
 	}
 	runVMTests(t, tests)
 }
+
+func TestCorruptedBytecode(t *testing.T) {
+	input := `let counter = fn() { let n = 0; fn() { n += 1; n } };
+let next = counter();
+let a = [1, 2.5, "three", {"k": [4]}];
+a[0] += next();
+let total = 0;
+for (x in [1, 2, 3]) { if (x == 2) { continue; } total = total + x; }
+let i = 0;
+while (true) { i = i + 1; if (i > 3) { break; } }
+let r = ""; try { throw("boom") } catch (e) { r = e.message } finally { total = total + 1 };
+let sum = fn(xs, acc) { if (len(xs) == 0) { acc } else { sum(tail(xs), acc + head(xs)) } };
+[a[1:], map(a[:1], fn(x) { x * 2 }), r, sum([1, 2, 3], 0), upper("x"), !true, -i, ~1]`
+
+	for _, level := range []int{compiler.O0, compiler.O1} {
+		comp := compiler.New()
+		comp.SetOptimization(level)
+		if err := comp.Compile(parse(input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+		encoded, err := comp.Bytecode().MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary failed: %s", err)
+		}
+
+		// The compiled program is valid
+		bytecode := &compiler.Bytecode{}
+		if err := bytecode.UnmarshalBinary(encoded); err != nil {
+			t.Fatalf("UnmarshalBinary failed (O%d): %s", level, err)
+		}
+		if err := New(bytecode).Run(); err != nil {
+			t.Fatalf("vm error (O%d): %s", level, err)
+		}
+
+		// Once a bit is flipped, the bytecode is either rejected or runs without crashing
+		config := Config{MaxStack: 1 << 12, MaxFrames: 64, Limits: data.Limits{Steps: 10000, Allocations: 100000}}
+		for i := range encoded {
+			for bit := 0; bit < 8; bit++ {
+				corrupted := append([]byte{}, encoded...)
+				corrupted[i] ^= 1 << bit
+
+				bytecode := &compiler.Bytecode{}
+				if bytecode.UnmarshalBinary(corrupted) != nil {
+					continue
+				}
+				NewWithConfig(bytecode, config).Run()
+			}
+		}
+	}
+
+	// Valid instructions may still fail (ex. popping from the empty stack), which try statements don't catch
+	instructions := append(operation.NewInstruction(operation.SetupTry, 5), operation.NewInstruction(operation.Pop)...)
+	instructions = append(instructions, operation.NewInstruction(operation.Pop)...)
+	encoded, err := (&compiler.Bytecode{Instructions: instructions}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %s", err)
+	}
+	bytecode := &compiler.Bytecode{}
+	if err := bytecode.UnmarshalBinary(encoded); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %s", err)
+	}
+	err = New(bytecode).Run()
+	if !errors.Is(err, ErrInvalidBytecode) {
+		t.Errorf("expected an invalid bytecode error, got %T (%v)", err, err)
+	}
+
+	// The same failure in bytecode not read from a file is a bug of the VM, which isn't hidden
+	defer func() {
+		if _, ok := recover().(runtime.Error); !ok {
+			t.Errorf("expected the failure of generated bytecode to panic")
+		}
+	}()
+	New(&compiler.Bytecode{Instructions: instructions}).Run()
+}
@@ -0,0 +1,51 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+// handler is an exception handler installed by a try statement
+type handler struct {
+	catch   int // The instruction handling the exception
+	frames  int // The number of frames when the handler was installed
+	pointer int // The stack pointer when the handler was installed
+}
+
+func (vm *VM) executeSetupTry(catch int) {
+	h := handler{catch: catch, frames: vm.frames.index, pointer: vm.stack.pointer}
+	vm.handlers = append(vm.handlers, h)
+}
+
+func (vm *VM) executePopTry() {
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+}
+
+func (vm *VM) executeThrow() error {
+	value := vm.stack.pop()
+	exception, ok := value.(*data.Exception)
+	if !ok {
+		return fmt.Errorf("unsupported type for throw: %s", value.Type())
+	}
+	return &thrownError{exception: exception}
+}
+
+// Unwinds the frames and the stack to the innermost handler and pushes the exception for it
+// Returns false if there is no handler
+func (vm *VM) catch(exception *data.Exception) bool {
+	if len(vm.handlers) == 0 {
+		return false
+	}
+
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+
+	vm.frames.index = h.frames
+	vm.stack.pointer = h.pointer
+	vm.frames.current().pointer = h.catch - 1
+
+	// The stack is at most as high as when the error was raised, so the push can't overflow
+	vm.stack.push(exception)
+	return true
+}
@@ -2,8 +2,17 @@ package data
 
 import "strings"
 
+// Array is a list of values backed by a persistent vector, so the arrays created from another one (with Push, Rest
+// or With) share most of its values instead of copying them
 type Array struct {
-	Elements []Data
+	values  vector
+	start   int // The index of the first value of the array in the vector (the values before were dropped by Rest)
+	created int // The values stored when the array was created, the others being shared (counted by the budgets)
+}
+
+// NewArray creates an array holding the given values (the slice is kept, so it must not be changed afterwards)
+func NewArray(elements []Data) *Array {
+	return &Array{values: vectorOf(elements), created: len(elements)}
 }
 
 func (ao *Array) Type() DataType { return ARRAY_TYPE }
@@ -12,7 +21,7 @@ func (ao *Array) Inspect() string {
 	var sb strings.Builder
 	elements := []string{}
 
-	for _, e := range ao.Elements {
+	for _, e := range ao.Elements() {
 		elements = append(elements, e.Inspect())
 	}
 
@@ -22,3 +31,43 @@ func (ao *Array) Inspect() string {
 
 	return sb.String()
 }
+
+// Len returns the number of elements
+func (ao *Array) Len() int {
+	return ao.values.count - ao.start
+}
+
+// Get returns the element at an index, which must be in range
+func (ao *Array) Get(i int) Data {
+	return ao.values.get(ao.start + i)
+}
+
+// Set replaces the element at an index (in range), the arrays sharing values with this one are left unchanged
+func (ao *Array) Set(i int, d Data) {
+	ao.values = ao.values.set(ao.start+i, d)
+}
+
+// With returns a new array with the element at an index (in range) replaced
+func (ao *Array) With(i int, d Data) *Array {
+	return &Array{values: ao.values.set(ao.start+i, d), start: ao.start, created: 1}
+}
+
+// Push returns a new array with an element added at the end
+func (ao *Array) Push(d Data) *Array {
+	return &Array{values: ao.values.push(d), start: ao.start, created: 1}
+}
+
+// Rest returns a new array without the first element (which must exist)
+func (ao *Array) Rest() *Array {
+	start := ao.start + 1
+	// Once most of the vector was dropped, the remaining values are copied so the dropped ones can be freed
+	if start > ao.values.count/2 {
+		return NewArray(ao.values.values(start))
+	}
+	return &Array{values: ao.values, start: start}
+}
+
+// Elements returns the elements in a new slice
+func (ao *Array) Elements() []Data {
+	return ao.values.values(ao.start)
+}
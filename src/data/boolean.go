@@ -12,6 +12,18 @@ func (b *Boolean) Type() DataType { return BOOLEAN_TYPE }
 
 func (b *Boolean) Inspect() string { return fmt.Sprintf("%t", b.Value) }
 
+// IsTruthy checks whether a value counts as true in conditions (every value but false and null)
+func IsTruthy(d Data) bool {
+	switch d := d.(type) {
+	case *Boolean:
+		return d.Value
+	case *Null:
+		return false
+	default:
+		return true
+	}
+}
+
 func (b *Boolean) Hash() uint64 {
 	if b.Value {
 		return 1
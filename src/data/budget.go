@@ -0,0 +1,125 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Limits are the resources a program may use, a zero value meaning no limit (except for CallDepth)
+type Limits struct {
+	Steps       int64 // The instructions executed by the VM (or the nodes evaluated by the interpreter)
+	Allocations int64 // The values created, counting the elements stored in new arrays, the pairs set in new hashes and the bytes of strings
+	CallDepth   int64 // The calls nested in the interpreter, DefaultCallDepth if zero (the VM uses its MaxFrames instead)
+}
+
+// DefaultCallDepth is the depth of the calls in the interpreter when the limits don't set one
+const DefaultCallDepth = 1 << 14
+
+// The steps between two checks of the context
+const contextCheckInterval = 1024
+
+// Budget tracks the resources used by a running program, stopping it once its context is done or it exceeds its limits
+type Budget struct {
+	ctx         context.Context
+	limits      Limits
+	steps       int64
+	allocations int64
+}
+
+func NewBudget(ctx context.Context, limits Limits) *Budget {
+	return &Budget{ctx: ctx, limits: limits}
+}
+
+// Limits returns the limits of the budget
+func (b *Budget) Limits() Limits {
+	return b.limits
+}
+
+// Step counts a step of the program, checking the context periodically
+func (b *Budget) Step() error {
+	b.steps++
+	if b.limits.Steps > 0 && b.steps > b.limits.Steps {
+		return &StepLimitError{Limit: b.limits.Steps}
+	}
+	if b.steps%contextCheckInterval == 1 {
+		if err := b.ctx.Err(); err != nil {
+			return &CanceledError{Err: err}
+		}
+	}
+	return nil
+}
+
+// Allocate counts the creation of a value
+// The arrays and hashes created from another one (ex. by push or set) only count what they don't share with it
+func (b *Budget) Allocate(d Data) error {
+	b.allocations += allocationSize(d)
+	if b.limits.Allocations > 0 && b.allocations > b.limits.Allocations {
+		return &AllocationLimitError{Limit: b.limits.Allocations}
+	}
+	return nil
+}
+
+// Reserve checks that values of the given size may still be created, before creating them (they are counted once created)
+func (b *Budget) Reserve(size int64) error {
+	if b.limits.Allocations > 0 && b.allocations+size > b.limits.Allocations {
+		return &AllocationLimitError{Limit: b.limits.Allocations}
+	}
+	return nil
+}
+
+func allocationSize(d Data) int64 {
+	switch d := d.(type) {
+	case *Array:
+		return 1 + int64(d.created)
+	case *Hash:
+		return 1 + int64(d.created)
+	case *String:
+		return 1 + int64(len(d.Value))
+	case *Closure:
+		return 1 + int64(len(d.Free))
+	case *Boolean, *Null, nil:
+		return 0 // shared, never allocated
+	default:
+		return 1
+	}
+}
+
+// StepLimitError is returned when a program runs more steps than its limit
+type StepLimitError struct {
+	Limit int64
+}
+
+func (e *StepLimitError) Error() string {
+	return fmt.Sprintf("step limit exceeded (%d steps)", e.Limit)
+}
+
+// AllocationLimitError is returned when a program creates more values than its limit
+type AllocationLimitError struct {
+	Limit int64
+}
+
+func (e *AllocationLimitError) Error() string {
+	return fmt.Sprintf("allocation limit exceeded (%d values)", e.Limit)
+}
+
+// CanceledError is returned when the context of a program is done (canceled or past its deadline)
+type CanceledError struct {
+	Err error // The error of the context
+}
+
+func (e *CanceledError) Error() string {
+	return "execution canceled: " + e.Err.Error()
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// IsLimitError checks whether an error stops a program for good (it can't be caught by the program)
+func IsLimitError(err error) bool {
+	var steps *StepLimitError
+	var allocations *AllocationLimitError
+	var canceled *CanceledError
+	return errors.As(err, &steps) || errors.As(err, &allocations) || errors.As(err, &canceled)
+}
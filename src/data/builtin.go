@@ -2,9 +2,38 @@ package data
 
 type BuiltinFn func(args ...Data) Data
 
+// HigherOrderFn is the function of a builtin calling the functions it is given (ex. map)
+type HigherOrderFn func(caller Caller, args ...Data) Data
+
+// Caller calls functions on behalf of a builtin, re-entering the VM or the evaluator running it
+// A failed call returns an *Error, which the builtin returns as is so the exception propagates
+type Caller interface {
+	Call(fn Data, args ...Data) Data
+}
+
 type Builtin struct {
-	Fn BuiltinFn
+	Fn          BuiltinFn
+	HigherOrder HigherOrderFn // Set instead of Fn by the builtins calling functions
+	// Size gives the allocations of a builtin which may create large values from small arguments (ex. repeat), so they
+	// are checked against the budget before the call
+	Size func(args ...Data) int64
 }
 
 func (b *Builtin) Type() DataType  { return BUILTIN_TYPE }
 func (b *Builtin) Inspect() string { return "builtin function" }
+
+// Reserve checks that the values the builtin creates from the arguments fit in a budget (nil if the program has no limits)
+func (b *Builtin) Reserve(budget *Budget, args []Data) error {
+	if b.Size == nil || budget == nil {
+		return nil
+	}
+	return budget.Reserve(b.Size(args...))
+}
+
+// Call calls the builtin, the caller calling the functions it is given
+func (b *Builtin) Call(caller Caller, args ...Data) Data {
+	if b.HigherOrder != nil {
+		return b.HigherOrder(caller, args...)
+	}
+	return b.Fn(args...)
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"fmt"
+	"unicode/utf8"
 )
 
 var Builtins = []struct {
@@ -14,6 +15,36 @@ var Builtins = []struct {
 	{"last", &Builtin{Fn: _last}},
 	{"push", &Builtin{Fn: _push}},
 	{"print", &Builtin{Fn: _print}},
+	{"byte_len", &Builtin{Fn: _byteLen}},
+	{"range", &Builtin{Fn: _range}},
+	{"throw", &Builtin{Fn: _throw}},
+	{"map", &Builtin{HigherOrder: _map}},
+	{"filter", &Builtin{HigherOrder: _filter}},
+	{"reduce", &Builtin{HigherOrder: _reduce}},
+	{"each", &Builtin{HigherOrder: _each}},
+	{"sort_by", &Builtin{HigherOrder: _sortBy}},
+	{"find", &Builtin{HigherOrder: _find}},
+	{"any", &Builtin{HigherOrder: _any}},
+	{"all", &Builtin{HigherOrder: _all}},
+	{"keys", &Builtin{Fn: _keys}},
+	{"values", &Builtin{Fn: _values}},
+	{"entries", &Builtin{Fn: _entries}},
+	{"has", &Builtin{Fn: _has}},
+	{"delete", &Builtin{Fn: _delete}},
+	{"merge", &Builtin{Fn: _merge}},
+	{"set", &Builtin{Fn: _set}},
+	{"split", &Builtin{Fn: _split}},
+	{"join", &Builtin{Fn: _join}},
+	{"trim", &Builtin{Fn: _trim}},
+	{"upper", &Builtin{Fn: _upper}},
+	{"lower", &Builtin{Fn: _lower}},
+	{"replace", &Builtin{Fn: _replace}},
+	{"contains", &Builtin{Fn: _contains}},
+	{"starts_with", &Builtin{Fn: _startsWith}},
+	{"ends_with", &Builtin{Fn: _endsWith}},
+	{"index_of", &Builtin{Fn: _indexOf}},
+	{"repeat", &Builtin{Fn: _repeat, Size: repeatSize}},
+	{"chars", &Builtin{Fn: _chars}},
 }
 
 func newError(format string, a ...interface{}) *Error {
@@ -35,14 +66,30 @@ func _len(args ...Data) Data {
 	}
 	switch arg := args[0].(type) {
 	case *Array:
-		return &Integer{Value: int64(len(arg.Elements))}
+		return &Integer{Value: int64(arg.Len())}
 	case *String:
-		return &Integer{Value: int64(len(arg.Value))}
+		return &Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *Hash:
+		return &Integer{Value: int64(arg.Len())}
+	case *Range:
+		return &Integer{Value: arg.Len()}
 	default:
 		return newError("argument to 'len' not supported, got %s", args[0].Type())
 	}
 }
 
+func _byteLen(args ...Data) Data {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	if args[0].Type() != STRING_TYPE {
+		return newError("argument to 'byte_len' must be STRING, got %s", args[0].Type())
+	}
+
+	return &Integer{Value: int64(len(args[0].(*String).Value))}
+}
+
 func _head(args ...Data) Data {
 	if len(args) != 1 {
 		return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -53,8 +100,8 @@ func _head(args ...Data) Data {
 	}
 
 	arr := args[0].(*Array)
-	if len(arr.Elements) > 0 {
-		return arr.Elements[0]
+	if arr.Len() > 0 {
+		return arr.Get(0)
 	}
 
 	return NULL
@@ -70,12 +117,8 @@ func _tail(args ...Data) Data {
 	}
 
 	arr := args[0].(*Array)
-	length := len(arr.Elements)
-
-	if length > 0 {
-		newElements := make([]Data, length-1, length-1)
-		copy(newElements, arr.Elements[1:length])
-		return &Array{Elements: newElements}
+	if arr.Len() > 0 {
+		return arr.Rest()
 	}
 
 	return NULL
@@ -91,10 +134,10 @@ func _last(args ...Data) Data {
 	}
 
 	arr := args[0].(*Array)
-	length := len(arr.Elements)
+	length := arr.Len()
 
 	if length > 0 {
-		return arr.Elements[length-1]
+		return arr.Get(length - 1)
 	}
 
 	return NULL
@@ -109,14 +152,7 @@ func _push(args ...Data) Data {
 		return newError("argument to 'push' must be ARRAY, got %s", args[0].Type())
 	}
 
-	arr := args[0].(*Array)
-	length := len(arr.Elements)
-	newElements := make([]Data, length+1, length+1)
-
-	copy(newElements, arr.Elements)
-	newElements[length] = args[1]
-
-	return &Array{Elements: newElements}
+	return args[0].(*Array).Push(args[1])
 }
 
 func _print(args ...Data) Data {
@@ -125,3 +161,198 @@ func _print(args ...Data) Data {
 	}
 	return NULL
 }
+
+func _range(args ...Data) Data {
+	if len(args) < 1 || len(args) > 3 {
+		return newError("wrong number of arguments. got=%d, want=1..3", len(args))
+	}
+
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		integer, ok := arg.(*Integer)
+		if !ok {
+			return newError("arguments to 'range' must be INTEGER, got %s", arg.Type())
+		}
+		bounds[i] = integer.Value
+	}
+
+	switch len(bounds) {
+	case 1:
+		return &Range{Start: 0, End: bounds[0], Step: 1}
+	case 2:
+		return &Range{Start: bounds[0], End: bounds[1], Step: 1}
+	default:
+		if bounds[2] == 0 {
+			return newError("'range' step must not be zero")
+		}
+		return &Range{Start: bounds[0], End: bounds[1], Step: bounds[2]}
+	}
+}
+
+func _throw(args ...Data) Data {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	var exception *Exception
+	switch arg := args[0].(type) {
+	case *Exception:
+		// Rethrowing a caught exception keeps its kind and stack trace
+		exception = arg
+	case *String:
+		exception = &Exception{Kind: ErrorKind, Message: arg.Value, Value: arg}
+	default:
+		exception = &Exception{Kind: ErrorKind, Message: arg.Inspect(), Value: arg}
+	}
+
+	return &Error{Message: exception.Message, Exception: exception}
+}
+
+// keys(h) returns the keys of a hash, in insertion order
+func _keys(args ...Data) Data {
+	hash, err := hashArg("keys", 1, args)
+	if err != nil {
+		return err
+	}
+
+	pairs := hash.Pairs()
+	elements := make([]Data, len(pairs))
+	for i, pair := range pairs {
+		elements[i] = pair.Key
+	}
+	return NewArray(elements)
+}
+
+// values(h) returns the values of a hash, in the order of its keys
+func _values(args ...Data) Data {
+	hash, err := hashArg("values", 1, args)
+	if err != nil {
+		return err
+	}
+
+	pairs := hash.Pairs()
+	elements := make([]Data, len(pairs))
+	for i, pair := range pairs {
+		elements[i] = pair.Value
+	}
+	return NewArray(elements)
+}
+
+// entries(h) returns the [key, value] pairs of a hash, in the order of its keys
+func _entries(args ...Data) Data {
+	hash, err := hashArg("entries", 1, args)
+	if err != nil {
+		return err
+	}
+
+	pairs := hash.Pairs()
+	elements := make([]Data, len(pairs))
+	for i, pair := range pairs {
+		elements[i] = NewArray([]Data{pair.Key, pair.Value})
+	}
+	return NewArray(elements)
+}
+
+// has(h, key) checks whether a hash has a key
+func _has(args ...Data) Data {
+	hash, err := hashArg("has", 2, args)
+	if err != nil {
+		return err
+	}
+
+	key, err := hashKey(args[1])
+	if err != nil {
+		return err
+	}
+	if _, ok := hash.Get(key); ok {
+		return TRUE
+	}
+	return FALSE
+}
+
+// delete(h, key) returns a copy of a hash without a key (the hash itself is left unchanged, like push does)
+func _delete(args ...Data) Data {
+	hash, err := hashArg("delete", 2, args)
+	if err != nil {
+		return err
+	}
+
+	key, err := hashKey(args[1])
+	if err != nil {
+		return err
+	}
+	result := hash.Copy()
+	result.Delete(key)
+	return result
+}
+
+// merge(a, b) returns a hash with the pairs of both hashes, those of b replacing those of a with the same keys
+// The keys keep the order they first appear in
+func _merge(args ...Data) Data {
+	a, err := hashArg("merge", 2, args)
+	if err != nil {
+		return err
+	}
+	b, ok := args[1].(*Hash)
+	if !ok {
+		return newError("arguments to 'merge' must be HASH, got %s", args[1].Type())
+	}
+
+	result := a.Copy()
+	for _, pair := range b.Pairs() {
+		result.Set(HashData(pair.Key.(HashableData)), pair)
+	}
+	return result
+}
+
+// set(xs, key, value) returns a copy of an array or a hash with the value at an index or a key replaced
+// The array or hash itself is left unchanged, and shares most of its elements with the copy
+func _set(args ...Data) Data {
+	if len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=3", len(args))
+	}
+
+	switch collection := args[0].(type) {
+	case *Array:
+		index, ok := args[1].(*Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", args[1].Type())
+		}
+		if index.Value < 0 || index.Value >= int64(collection.Len()) {
+			return newError("array index out of range: %d", index.Value)
+		}
+		return collection.With(int(index.Value), args[2])
+
+	case *Hash:
+		key, err := hashKey(args[1])
+		if err != nil {
+			return err
+		}
+		result := collection.Copy()
+		result.Set(key, HashPair{Key: args[1], Value: args[2]})
+		return result
+
+	default:
+		return newError("argument to 'set' must be ARRAY or HASH, got %s", args[0].Type())
+	}
+}
+
+// Checks the arguments of a builtin taking a hash first
+func hashArg(name string, count int, args []Data) (*Hash, *Error) {
+	if len(args) != count {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), count)
+	}
+	hash, ok := args[0].(*Hash)
+	if !ok {
+		return nil, newError("argument to '%s' must be HASH, got %s", name, args[0].Type())
+	}
+	return hash, nil
+}
+
+func hashKey(d Data) (HashKey, *Error) {
+	hashable, ok := d.(HashableData)
+	if !ok {
+		return HashKey{}, newError("unusable as hash key: %s", d.Type())
+	}
+	return HashData(hashable), nil
+}
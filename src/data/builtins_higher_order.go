@@ -0,0 +1,207 @@
+package data
+
+import (
+	"sort"
+)
+
+func _map(caller Caller, args ...Data) Data {
+	iterator, err := higherOrderArgs("map", 2, args)
+	if err != nil {
+		return err
+	}
+
+	elements := []Data{}
+	for value, ok := iterator.Next(); ok; value, ok = iterator.Next() {
+		result := caller.Call(args[1], value)
+		if _, failed := result.(*Error); failed {
+			return result
+		}
+		elements = append(elements, result)
+	}
+	return NewArray(elements)
+}
+
+func _filter(caller Caller, args ...Data) Data {
+	iterator, err := higherOrderArgs("filter", 2, args)
+	if err != nil {
+		return err
+	}
+
+	elements := []Data{}
+	for value, ok := iterator.Next(); ok; value, ok = iterator.Next() {
+		result := caller.Call(args[1], value)
+		if _, failed := result.(*Error); failed {
+			return result
+		}
+		if IsTruthy(result) {
+			elements = append(elements, value)
+		}
+	}
+	return NewArray(elements)
+}
+
+// reduce(xs, f, initial) folds the values with f(accumulator, value), starting from the first value without initial
+func _reduce(caller Caller, args ...Data) Data {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=2..3", len(args))
+	}
+	iterator, err := higherOrderArgs("reduce", len(args), args)
+	if err != nil {
+		return err
+	}
+
+	var accumulator Data = NULL
+	if len(args) == 3 {
+		accumulator = args[2]
+	} else if first, ok := iterator.Next(); ok {
+		accumulator = first
+	}
+
+	for value, ok := iterator.Next(); ok; value, ok = iterator.Next() {
+		accumulator = caller.Call(args[1], accumulator, value)
+		if _, failed := accumulator.(*Error); failed {
+			return accumulator
+		}
+	}
+	return accumulator
+}
+
+func _each(caller Caller, args ...Data) Data {
+	iterator, err := higherOrderArgs("each", 2, args)
+	if err != nil {
+		return err
+	}
+
+	for value, ok := iterator.Next(); ok; value, ok = iterator.Next() {
+		result := caller.Call(args[1], value)
+		if _, failed := result.(*Error); failed {
+			return result
+		}
+	}
+	return NULL
+}
+
+// sort_by(xs, f) sorts the values by the keys f returns for them (all numbers or all strings), keeping the order of equal keys
+func _sortBy(caller Caller, args ...Data) Data {
+	iterator, err := higherOrderArgs("sort_by", 2, args)
+	if err != nil {
+		return err
+	}
+
+	values, keys := []Data{}, []Data{}
+	for value, ok := iterator.Next(); ok; value, ok = iterator.Next() {
+		key := caller.Call(args[1], value)
+		if _, failed := key.(*Error); failed {
+			return key
+		}
+		values = append(values, value)
+		keys = append(keys, key)
+	}
+
+	for _, key := range keys {
+		if !comparableKeys(keys[0], key) {
+			return newError("keys of 'sort_by' must be all numbers or all strings, got %s and %s", keys[0].Type(), key.Type())
+		}
+	}
+
+	indexes := make([]int, len(values))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return lessKey(keys[indexes[i]], keys[indexes[j]])
+	})
+
+	elements := make([]Data, len(values))
+	for i, index := range indexes {
+		elements[i] = values[index]
+	}
+	return NewArray(elements)
+}
+
+// find(xs, f) returns the first value f is truthy for (null if there is none)
+func _find(caller Caller, args ...Data) Data {
+	iterator, err := higherOrderArgs("find", 2, args)
+	if err != nil {
+		return err
+	}
+
+	for value, ok := iterator.Next(); ok; value, ok = iterator.Next() {
+		result := caller.Call(args[1], value)
+		if _, failed := result.(*Error); failed {
+			return result
+		}
+		if IsTruthy(result) {
+			return value
+		}
+	}
+	return NULL
+}
+
+func _any(caller Caller, args ...Data) Data {
+	return matches("any", caller, args, true)
+}
+
+func _all(caller Caller, args ...Data) Data {
+	return matches("all", caller, args, false)
+}
+
+// Checks whether f is truthy for any value, or for all values (stopping at the first value deciding it)
+func matches(name string, caller Caller, args []Data, any bool) Data {
+	iterator, err := higherOrderArgs(name, 2, args)
+	if err != nil {
+		return err
+	}
+
+	for value, ok := iterator.Next(); ok; value, ok = iterator.Next() {
+		result := caller.Call(args[1], value)
+		if _, failed := result.(*Error); failed {
+			return result
+		}
+		if IsTruthy(result) == any {
+			return nativeBoolean(any)
+		}
+	}
+	return nativeBoolean(!any)
+}
+
+// Checks the arguments of a higher-order builtin (an iterable and a function), returning an iterator over the iterable
+func higherOrderArgs(name string, want int, args []Data) (*Iterator, *Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+
+	iterator, ok := NewIterator(args[0])
+	if !ok {
+		return nil, newError("first argument to '%s' must be iterable, got %s", name, args[0].Type())
+	}
+	switch args[1].(type) {
+	case *Closure, *Function, *Builtin:
+		return iterator, nil
+	default:
+		return nil, newError("second argument to '%s' must be a function, got %s", name, args[1].Type())
+	}
+}
+
+func nativeBoolean(value bool) *Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
+func comparableKeys(a, b Data) bool {
+	_, aString := a.(*String)
+	_, bString := b.(*String)
+	if aString || bString {
+		return aString && bString
+	}
+	_, aNumber := NumberValue(a)
+	_, bNumber := NumberValue(b)
+	return aNumber && bNumber
+}
+
+func lessKey(a, b Data) bool {
+	order, _ := Compare(a, b)
+	return order == Less
+}
@@ -0,0 +1,186 @@
+package data
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// split(s, sep) returns the parts of a string between the separators (its characters if the separator is empty)
+func _split(args ...Data) Data {
+	values, err := stringArgs("split", 2, args)
+	if err != nil {
+		return err
+	}
+	return stringArray(strings.Split(values[0], values[1]))
+}
+
+// join(xs, sep) returns the strings of an array joined by a separator
+func _join(args ...Data) Data {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	array, ok := args[0].(*Array)
+	if !ok {
+		return newError("first argument to 'join' must be ARRAY, got %s", args[0].Type())
+	}
+	sep, ok := args[1].(*String)
+	if !ok {
+		return newError("second argument to 'join' must be STRING, got %s", args[1].Type())
+	}
+
+	parts := make([]string, array.Len())
+	for i, element := range array.Elements() {
+		str, ok := element.(*String)
+		if !ok {
+			return newError("elements joined by 'join' must be STRING, got %s", element.Type())
+		}
+		parts[i] = str.Value
+	}
+	return &String{Value: strings.Join(parts, sep.Value)}
+}
+
+// trim(s) returns a string without its leading and trailing white space
+func _trim(args ...Data) Data {
+	values, err := stringArgs("trim", 1, args)
+	if err != nil {
+		return err
+	}
+	return &String{Value: strings.TrimSpace(values[0])}
+}
+
+func _upper(args ...Data) Data {
+	values, err := stringArgs("upper", 1, args)
+	if err != nil {
+		return err
+	}
+	return &String{Value: strings.ToUpper(values[0])}
+}
+
+func _lower(args ...Data) Data {
+	values, err := stringArgs("lower", 1, args)
+	if err != nil {
+		return err
+	}
+	return &String{Value: strings.ToLower(values[0])}
+}
+
+// replace(s, old, new) returns a string with every occurrence of old replaced by new
+func _replace(args ...Data) Data {
+	values, err := stringArgs("replace", 3, args)
+	if err != nil {
+		return err
+	}
+	return &String{Value: strings.ReplaceAll(values[0], values[1], values[2])}
+}
+
+func _contains(args ...Data) Data {
+	values, err := stringArgs("contains", 2, args)
+	if err != nil {
+		return err
+	}
+	return nativeBoolean(strings.Contains(values[0], values[1]))
+}
+
+func _startsWith(args ...Data) Data {
+	values, err := stringArgs("starts_with", 2, args)
+	if err != nil {
+		return err
+	}
+	return nativeBoolean(strings.HasPrefix(values[0], values[1]))
+}
+
+func _endsWith(args ...Data) Data {
+	values, err := stringArgs("ends_with", 2, args)
+	if err != nil {
+		return err
+	}
+	return nativeBoolean(strings.HasSuffix(values[0], values[1]))
+}
+
+// index_of(s, sub) returns the index (in characters) of the first occurrence of sub in a string, or -1
+func _indexOf(args ...Data) Data {
+	values, err := stringArgs("index_of", 2, args)
+	if err != nil {
+		return err
+	}
+
+	i := strings.Index(values[0], values[1])
+	if i < 0 {
+		return &Integer{Value: -1}
+	}
+	return &Integer{Value: int64(utf8.RuneCountInString(values[0][:i]))}
+}
+
+// repeat(s, n) returns a string repeated n times
+func _repeat(args ...Data) Data {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	str, ok := args[0].(*String)
+	if !ok {
+		return newError("first argument to 'repeat' must be STRING, got %s", args[0].Type())
+	}
+	count, ok := args[1].(*Integer)
+	if !ok {
+		return newError("second argument to 'repeat' must be INTEGER, got %s", args[1].Type())
+	}
+
+	if count.Value < 0 {
+		return newError("'repeat' count must not be negative, got %d", count.Value)
+	}
+	if len(str.Value) > 0 && count.Value > math.MaxInt32/int64(len(str.Value)) {
+		return newError("'repeat' result too long (%d times %d bytes)", count.Value, len(str.Value))
+	}
+	return &String{Value: strings.Repeat(str.Value, int(count.Value))}
+}
+
+// The size of the string created by repeat, checked before it is created (0 for the arguments repeat rejects)
+func repeatSize(args ...Data) int64 {
+	if len(args) != 2 {
+		return 0
+	}
+	str, isString := args[0].(*String)
+	count, isInteger := args[1].(*Integer)
+	if !isString || !isInteger || count.Value < 0 || (len(str.Value) > 0 && count.Value > math.MaxInt32/int64(len(str.Value))) {
+		return 0
+	}
+	return 1 + count.Value*int64(len(str.Value))
+}
+
+// chars(s) returns the characters of a string
+func _chars(args ...Data) Data {
+	values, err := stringArgs("chars", 1, args)
+	if err != nil {
+		return err
+	}
+	return stringArray(strings.Split(values[0], ""))
+}
+
+// Checks the arguments of a builtin taking only strings
+func stringArgs(name string, count int, args []Data) ([]string, *Error) {
+	if len(args) != count {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), count)
+	}
+
+	values := make([]string, count)
+	for i, arg := range args {
+		str, ok := arg.(*String)
+		if !ok {
+			if count == 1 {
+				return nil, newError("argument to '%s' must be STRING, got %s", name, arg.Type())
+			}
+			return nil, newError("arguments to '%s' must be STRING, got %s", name, arg.Type())
+		}
+		values[i] = str.Value
+	}
+	return values, nil
+}
+
+func stringArray(values []string) *Array {
+	elements := make([]Data, len(values))
+	for i, value := range values {
+		elements[i] = &String{Value: value}
+	}
+	return NewArray(elements)
+}
@@ -0,0 +1,9 @@
+package data
+
+// Cell holds a variable captured by closures, so updates are shared between them and the enclosing function
+type Cell struct {
+	Value Data
+}
+
+func (c *Cell) Type() DataType  { return CELL_TYPE }
+func (c *Cell) Inspect() string { return c.Value.Inspect() }
@@ -0,0 +1,208 @@
+package data
+
+import "strings"
+
+// Order is the result of comparing two values
+type Order int
+
+const (
+	Less      Order = -1
+	Same      Order = 0
+	Greater   Order = 1
+	Unordered Order = 2 // A comparison involving NaN, every ordering operator is false
+)
+
+// The depth past which Equal and Compare start tracking the pairs they compare, so values containing themselves don't
+// recurse forever
+const cycleDepthCheck = 64
+
+// Equal checks whether two values are equal
+// Numbers are equal by value (an integer and a float included), strings by content, arrays by their elements and hashes
+// by their pairs (in any order), other values only to themselves; values of different types are never equal
+func Equal(a, b Data) bool {
+	return (&comparer{}).equal(a, b, 0)
+}
+
+type comparer struct {
+	seen map[[2]Data]bool // The pairs being compared, once deep enough
+}
+
+func (e *comparer) equal(a, b Data, depth int) bool {
+	if a == b {
+		if f, ok := a.(*Float); ok {
+			return f.Value == f.Value // NaN
+		}
+		return true
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Value == b.Value
+		case *Float:
+			return float64(a.Value) == b.Value
+		}
+		return false
+
+	case *Float:
+		if y, ok := NumberValue(b); ok {
+			return a.Value == y
+		}
+		return false
+
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		if e.comparing(a, b, depth) {
+			return true
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !e.equal(a.Get(i), b.Get(i), depth+1) {
+				return false
+			}
+		}
+		return true
+
+	case *Hash:
+		b, ok := b.(*Hash)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		if e.comparing(a, b, depth) {
+			return true
+		}
+		for _, pair := range a.Pairs() {
+			other, found := b.Get(HashData(pair.Key.(HashableData)))
+			if !found || !e.equal(pair.Value, other.Value, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// Checks whether two containers are already being compared (then assumed equal), marking them otherwise
+func (e *comparer) comparing(a, b Data, depth int) bool {
+	if depth < cycleDepthCheck {
+		return false
+	}
+	if e.seen == nil {
+		e.seen = map[[2]Data]bool{}
+	}
+	pair := [2]Data{a, b}
+	if e.seen[pair] {
+		return true
+	}
+	e.seen[pair] = true
+	return false
+}
+
+// Compare orders two values, ok is false if they can't be ordered
+// Numbers are ordered by value, strings by their bytes and arrays lexicographically (by their first differing element,
+// or their length); other values and values of different types aren't ordered
+func Compare(a, b Data) (order Order, ok bool) {
+	return (&comparer{}).compare(a, b, 0)
+}
+
+func (e *comparer) compare(a, b Data, depth int) (order Order, ok bool) {
+	switch a := a.(type) {
+	case *Integer:
+		if b, isInteger := b.(*Integer); isInteger {
+			return orderOf(a.Value, b.Value), true
+		}
+		if y, isNumber := NumberValue(b); isNumber {
+			return orderFloats(float64(a.Value), y), true
+		}
+
+	case *Float:
+		if y, isNumber := NumberValue(b); isNumber {
+			return orderFloats(a.Value, y), true
+		}
+
+	case *String:
+		if b, isString := b.(*String); isString {
+			return Order(strings.Compare(a.Value, b.Value)), true
+		}
+
+	case *Array:
+		if b, isArray := b.(*Array); isArray {
+			if e.comparing(a, b, depth) {
+				return Same, true
+			}
+			for i := 0; i < a.Len() && i < b.Len(); i++ {
+				order, ok := e.compare(a.Get(i), b.Get(i), depth+1)
+				if !ok || order != Same {
+					return order, ok
+				}
+			}
+			return orderOf(int64(a.Len()), int64(b.Len())), true
+		}
+	}
+
+	return Unordered, false
+}
+
+// Comparison applies a comparison operator (==, !=, <, <=, > or >=) to two values, ok is false if they can't be ordered
+// It is the one implementation of the comparisons, shared by the VM, the interpreter and the constant folding
+func Comparison(operator string, a, b Data) (result bool, ok bool) {
+	switch operator {
+	case "==":
+		return Equal(a, b), true
+	case "!=":
+		return !Equal(a, b), true
+	}
+
+	order, ok := Compare(a, b)
+	if !ok {
+		return false, false
+	}
+	switch operator {
+	case "<":
+		return order == Less, true
+	case "<=":
+		return order == Less || order == Same, true
+	case ">":
+		return order == Greater, true
+	case ">=":
+		return order == Greater || order == Same, true
+	default:
+		return false, false
+	}
+}
+
+func orderOf(a, b int64) Order {
+	switch {
+	case a < b:
+		return Less
+	case a > b:
+		return Greater
+	default:
+		return Same
+	}
+}
+
+func orderFloats(a, b float64) Order {
+	switch {
+	case a < b:
+		return Less
+	case a > b:
+		return Greater
+	case a == b:
+		return Same
+	default:
+		return Unordered
+	}
+}
@@ -2,14 +2,24 @@ package data
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/ape-lang/ape/src/compiler/operation"
+	"github.com/ape-lang/ape/src/token"
 )
 
 type CompiledFunction struct {
 	Instructions operation.Instruction
-	LocalCount   int // local variable definitions count (needed to allocate space for the function in the stack)
-	ParamCount   int // parameter count
+	LocalCount   int              // local variable definitions count (needed to allocate space for the function in the stack)
+	ParamCount   int              // parameter count
+	Name         string           // the name the function is bound to (empty for anonymous functions)
+	Positions    []SourcePosition // maps the instructions to the source they were compiled from
+}
+
+// SourcePosition maps the instructions starting at an offset to a source position
+type SourcePosition struct {
+	Offset   int
+	Position token.Position
 }
 
 func (cf *CompiledFunction) Type() DataType { return COMPILED_FUNCTION_TYPE }
@@ -17,3 +27,15 @@ func (cf *CompiledFunction) Type() DataType { return COMPILED_FUNCTION_TYPE }
 func (cf *CompiledFunction) Inspect() string {
 	return fmt.Sprintf("CompiledFunction[%p]", cf)
 }
+
+// PositionAt returns the source position of the instruction at the given offset
+func (cf *CompiledFunction) PositionAt(offset int) token.Position {
+	// Find the first entry past the offset, the one before it covers the instruction
+	i := sort.Search(len(cf.Positions), func(i int) bool {
+		return cf.Positions[i].Offset > offset
+	})
+	if i == 0 {
+		return token.Position{}
+	}
+	return cf.Positions[i-1].Position
+}
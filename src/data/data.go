@@ -4,6 +4,7 @@ package data
 
 const (
 	INTEGER_TYPE           = "INTEGER"
+	FLOAT_TYPE             = "FLOAT"
 	BOOLEAN_TYPE           = "BOOLEAN"
 	NULL_TYPE              = "NULL"
 	RETURN_TYPE            = "RETURN"
@@ -15,6 +16,13 @@ const (
 	HASH_TYPE              = "HASH"
 	COMPILED_FUNCTION_TYPE = "COMPILED_TYPE"
 	CLOSURE_TYPE           = "CLOSURE_TYPE"
+	RANGE_TYPE             = "RANGE"
+	ITERATOR_TYPE          = "ITERATOR"
+	BREAK_TYPE             = "BREAK"
+	CONTINUE_TYPE          = "CONTINUE"
+	CELL_TYPE              = "CELL"
+	EXCEPTION_TYPE         = "EXCEPTION"
+	MODULE_TYPE            = "MODULE"
 )
 
 // Global references, so a new object does not get allocated for each evaluation
@@ -22,6 +30,9 @@ var (
 	TRUE  = &Boolean{Value: true}
 	FALSE = &Boolean{Value: false}
 	NULL  = &Null{}
+
+	BREAK    = &Break{}
+	CONTINUE = &Continue{}
 )
 
 type DataType string
@@ -1,6 +1,9 @@
 package data
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -20,3 +23,263 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("Expected same hash for strings with the same content")
 	}
 }
+
+func TestFloatHashKey(t *testing.T) {
+	if HashData(&Float{Value: 1.5}) != HashData(&Float{Value: 1.5}) {
+		t.Errorf("Expected same hash for floats with the same value")
+	}
+
+	if HashData(&Float{Value: 0.0}) != HashData(&Float{Value: -0.0}) {
+		t.Errorf("Expected same hash for positive and negative zero")
+	}
+
+	if HashData(&Float{Value: 1.5}) == HashData(&Float{Value: 2.5}) {
+		t.Errorf("Expected different hash for floats with different values")
+	}
+
+	if HashData(&Float{Value: 2}) != HashData(&Integer{Value: 2}) {
+		t.Errorf("Expected same hash for an integral float and the equal integer")
+	}
+
+	if HashData(&Float{Value: 1e300}) == HashData(&Integer{Value: 0}) {
+		t.Errorf("Expected different hash for a float out of the range of integers")
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{3.14, "3.14"},
+		{2, "2.0"},
+		{-0.5, "-0.5"},
+		{1e-9, "1e-09"},
+		{1e21, "1e+21"},
+	}
+
+	for _, tt := range tests {
+		result := (&Float{Value: tt.value}).Inspect()
+		if result != tt.expected {
+			t.Errorf("Expected Inspect() to be %q, got %q", tt.expected, result)
+		}
+	}
+}
+
+func TestRangeIterator(t *testing.T) {
+	tests := []struct {
+		r        *Range
+		expected []int64
+	}{
+		{&Range{Start: 0, End: 3, Step: 1}, []int64{0, 1, 2}},
+		{&Range{Start: 1, End: 8, Step: 3}, []int64{1, 4, 7}},
+		{&Range{Start: 5, End: 0, Step: -2}, []int64{5, 3, 1}},
+		{&Range{Start: 3, End: 3, Step: 1}, []int64{}},
+		{&Range{Start: 3, End: 0, Step: 1}, []int64{}},
+	}
+
+	for _, tt := range tests {
+		if tt.r.Len() != int64(len(tt.expected)) {
+			t.Errorf("Expected %s to have length %d, got %d", tt.r.Inspect(), len(tt.expected), tt.r.Len())
+		}
+
+		iterator, _ := NewIterator(tt.r)
+		for _, expected := range tt.expected {
+			value, ok := iterator.Next()
+			if !ok || value.(*Integer).Value != expected {
+				t.Fatalf("Expected %s to yield %d, got %v", tt.r.Inspect(), expected, value)
+			}
+		}
+		if _, ok := iterator.Next(); ok {
+			t.Errorf("Expected %s to be exhausted", tt.r.Inspect())
+		}
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	hash := NewHash(0)
+	set := func(key string, value int64) {
+		k := &String{Value: key}
+		hash.Set(HashData(k), HashPair{Key: k, Value: &Integer{Value: value}})
+	}
+	remove := func(key string) bool {
+		return hash.Delete(HashData(&String{Value: key}))
+	}
+
+	set("c", 1)
+	set("a", 2)
+	set("b", 3)
+	set("a", 4) // an existing key keeps its position
+	if hash.Inspect() != "{c: 1, a: 4, b: 3}" {
+		t.Errorf("wrong order: %s", hash.Inspect())
+	}
+
+	if !remove("a") || remove("a") || remove("z") {
+		t.Errorf("wrong deletion results")
+	}
+	set("a", 5) // a deleted key is inserted again at the end
+	if hash.Inspect() != "{c: 1, b: 3, a: 5}" || hash.Len() != 3 {
+		t.Errorf("wrong pairs after deletion: %s (len %d)", hash.Inspect(), hash.Len())
+	}
+
+	// Deleting most pairs compacts them, keeping the order and the lookups
+	for _, key := range []string{"c", "b"} {
+		remove(key)
+	}
+	set("d", 6)
+	if hash.order.count != 2 || hash.Inspect() != "{a: 5, d: 6}" {
+		t.Errorf("wrong pairs after compaction: %s (%d stored)", hash.Inspect(), hash.order.count)
+	}
+	if pair, ok := hash.Get(HashData(&String{Value: "d"})); !ok || pair.Value.(*Integer).Value != 6 {
+		t.Errorf("wrong lookup after compaction: %v, %v", pair, ok)
+	}
+}
+
+func TestArrayPersistence(t *testing.T) {
+	// Sizes around the edges of the tail, of a full root and of a second level
+	for _, size := range []int{0, 1, 31, 32, 33, 1024, 1056, 1057, 33000} {
+		array := NewArray(nil)
+		for i := 0; i < size; i++ {
+			array = array.Push(&Integer{Value: int64(i)})
+		}
+		built := NewArray(array.Elements())
+
+		if array.Len() != size || built.Len() != size {
+			t.Fatalf("wrong length: want=%d, got=%d and %d", size, array.Len(), built.Len())
+		}
+		for i := 0; i < size; i++ {
+			if array.Get(i).(*Integer).Value != int64(i) || built.Get(i).(*Integer).Value != int64(i) {
+				t.Fatalf("wrong element %d of %d", i, size)
+			}
+		}
+		if size == 0 {
+			continue
+		}
+
+		// Updates leave the original array unchanged
+		changed := array.With(size/2, TRUE).Push(FALSE)
+		rest := array.Rest()
+		built.Set(size-1, NULL)
+		if array.Get(size/2) == TRUE || array.Len() != size || array.Get(size-1) == NULL {
+			t.Errorf("the original array of %d was changed", size)
+		}
+		if changed.Get(size/2) != TRUE || changed.Get(size) != FALSE || changed.Len() != size+1 {
+			t.Errorf("wrong changed array of %d", size)
+		}
+		if rest.Len() != size-1 || (size > 1 && rest.Get(0).(*Integer).Value != 1) {
+			t.Errorf("wrong rest of %d: %d elements", size, rest.Len())
+		}
+		if built.Get(size-1) != NULL {
+			t.Errorf("wrong element after setting it in place")
+		}
+
+		// Dropping the elements one by one keeps the following ones
+		for i := 1; rest.Len() > 0; i++ {
+			if rest.Get(0).(*Integer).Value != int64(i) {
+				t.Fatalf("wrong first element after %d rests: %s", i, rest.Get(0).Inspect())
+			}
+			rest = rest.Rest()
+		}
+	}
+}
+
+// Like the arrays program of the benchmark util: builds an array of 100k elements with Push, then sums it with Rest
+// (which copied the whole array on every call before arrays shared their elements)
+func BenchmarkArrayPushRest(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		array := NewArray(nil)
+		for i := 0; i < 100000; i++ {
+			array = array.Push(&Integer{Value: int64(i)})
+		}
+
+		sum := int64(0)
+		for array.Len() > 0 {
+			sum += array.Get(0).(*Integer).Value
+			array = array.Rest()
+		}
+		if sum != 4999950000 {
+			b.Fatalf("wrong sum: %d", sum)
+		}
+	}
+}
+
+func TestHashPersistence(t *testing.T) {
+	hash := NewHash(0)
+	for i := 0; i < 5000; i++ {
+		key := &Integer{Value: int64(i)}
+		hash.Set(HashData(key), HashPair{Key: key, Value: key})
+	}
+	other := hash.Copy()
+	for i := 0; i < 5000; i += 2 {
+		other.Delete(HashData(&Integer{Value: int64(i)}))
+	}
+
+	if hash.Len() != 5000 || other.Len() != 2500 {
+		t.Fatalf("wrong lengths: %d and %d", hash.Len(), other.Len())
+	}
+	for i := 0; i < 5000; i++ {
+		_, inHash := hash.Get(HashData(&Integer{Value: int64(i)}))
+		_, inCopy := other.Get(HashData(&Integer{Value: int64(i)}))
+		if !inHash || inCopy != (i%2 == 1) {
+			t.Fatalf("wrong lookups of %d: %t and %t", i, inHash, inCopy)
+		}
+	}
+	if pairs := other.Pairs(); pairs[0].Key.(*Integer).Value != 1 || pairs[len(pairs)-1].Key.(*Integer).Value != 4999 {
+		t.Errorf("wrong order after deletions: %s...", pairs[0].Key.Inspect())
+	}
+
+	// Keys with equal hashes are kept apart
+	var node *hamtNode
+	for i := 0; i < 3; i++ {
+		node, _ = node.set(hamtEntry{key: HashKey{Type: INTEGER_TYPE, Value: uint64(i)}, hash: 42, position: i}, 0)
+	}
+	node, _ = node.remove(HashKey{Type: INTEGER_TYPE, Value: 1}, 42, 0)
+	for i, found := range []bool{true, false, true} {
+		entry, ok := node.get(HashKey{Type: INTEGER_TYPE, Value: uint64(i)}, 42)
+		if ok != found || (ok && entry.position != i) {
+			t.Errorf("wrong lookup of colliding key %d: %t", i, ok)
+		}
+	}
+}
+
+func TestBudgetAllocations(t *testing.T) {
+	large := NewArray(make([]Data, 100000))
+	hash := NewHash(0)
+	for i := 0; i < 3; i++ {
+		key := &Integer{Value: int64(i)}
+		hash.Set(HashData(key), HashPair{Key: key, Value: key})
+	}
+	merged := hash.Copy()
+	for _, key := range []Data{&Integer{Value: 3}, &Integer{Value: 4}} {
+		merged.Set(HashData(key.(HashableData)), HashPair{Key: key, Value: key})
+	}
+	deleted := hash.Copy()
+	deleted.Delete(HashData(&Integer{Value: 0}))
+
+	tests := []struct {
+		name     string
+		value    Data
+		expected int64
+	}{
+		{"array", large, 100001},
+		{"push", large.Push(TRUE), 2},
+		{"with", large.With(0, TRUE), 2},
+		{"rest", large.Rest(), 1},
+		{"rest copying", NewArray(make([]Data, 3)).Rest().Rest(), 2},
+		{"hash", hash, 4},
+		{"merged hash", merged, 3},
+		{"deleted", deleted, 1},
+		{"string", &String{Value: "abc"}, 4},
+		{"boolean", TRUE, 0},
+	}
+
+	for _, tt := range tests {
+		budget := NewBudget(context.Background(), Limits{})
+		if err := budget.Allocate(tt.value); err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.name, err)
+		}
+		if budget.allocations != tt.expected {
+			t.Errorf("wrong charge for %s: expected=%d, got=%d", tt.name, tt.expected, budget.allocations)
+		}
+	}
+}
@@ -1,8 +1,24 @@
 package data
 
+import (
+	"github.com/ape-lang/ape/src/token"
+)
+
 type Environment struct {
-	store map[string]Data
-	outer *Environment
+	store   map[string]Data
+	outer   *Environment
+	call    *CallInfo // Set for the environments of function calls
+	imports *Imports  // Set for the root environments of programs and modules
+	budget  *Budget   // Set on the root environment of a program run with limits (the calls keep it in their CallInfo)
+}
+
+// CallInfo links the environment of a function call to the environment it was called from
+type CallInfo struct {
+	Function string
+	Position token.Position // The position of the call expression
+	Caller   *Environment
+	Depth    int     // The number of calls active, this one included
+	Budget   *Budget // The budget of the caller, so it is found without walking to the root environment
 }
 
 func NewEnvironmentClosure(outer *Environment) *Environment {
@@ -11,11 +27,81 @@ func NewEnvironmentClosure(outer *Environment) *Environment {
 	return env
 }
 
+// NewCallEnvironment creates the environment of a function call
+func NewCallEnvironment(outer *Environment, call *CallInfo) *Environment {
+	env := NewEnvironmentClosure(outer)
+	env.call = call
+	return env
+}
+
 func NewEnvironment() *Environment {
 	s := make(map[string]Data)
 	return &Environment{store: s, outer: nil}
 }
 
+// NewModuleEnvironment creates the root environment of an imported module
+func NewModuleEnvironment(imports *Imports, call *CallInfo) *Environment {
+	env := NewEnvironment()
+	env.imports = imports
+	env.call = call
+	return env
+}
+
+// Imports returns the modules imported by the program the environment belongs to (nil until set)
+func (e *Environment) Imports() *Imports {
+	return e.root().imports
+}
+
+// SetImports sets the modules imported by the program the environment belongs to
+func (e *Environment) SetImports(imports *Imports) {
+	e.root().imports = imports
+}
+
+// SetBudget sets the budget of the program the environment belongs to (nil for no limits)
+func (e *Environment) SetBudget(budget *Budget) {
+	env := e.frame()
+	if env.call != nil {
+		env.call.Budget = budget
+		return
+	}
+	env.budget = budget
+}
+
+// Budget returns the budget of the program the environment belongs to (nil if it has no limits)
+// Modules have their own root environment, but their import keeps the budget of the program importing them
+func (e *Environment) Budget() *Budget {
+	env := e.frame()
+	if env.call != nil {
+		return env.call.Budget
+	}
+	return env.budget
+}
+
+// Depth returns the number of calls (and module imports) active where the environment is used
+func (e *Environment) Depth() int {
+	if env := e.frame(); env.call != nil {
+		return env.call.Depth
+	}
+	return 0
+}
+
+// Returns the environment of the innermost call the environment belongs to (the root environment outside of calls)
+func (e *Environment) frame() *Environment {
+	env := e
+	for env.call == nil && env.outer != nil {
+		env = env.outer
+	}
+	return env
+}
+
+func (e *Environment) root() *Environment {
+	env := e
+	for env.outer != nil {
+		env = env.outer
+	}
+	return env
+}
+
 func (e *Environment) Get(name string) (Data, bool) {
 	data, ok := e.store[name]
 	if !ok && e.outer != nil {
@@ -28,3 +114,33 @@ func (e *Environment) Set(name string, val Data) Data {
 	e.store[name] = val
 	return val
 }
+
+// Assign updates an existing variable in the environment where it was defined
+func (e *Environment) Assign(name string, val Data) bool {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return false
+}
+
+// Trace returns the stack trace of the calls leading to the environment, innermost first
+func (e *Environment) Trace(pos token.Position) []TraceFrame {
+	trace := []TraceFrame{}
+
+	env := e
+	for env.call != nil || env.outer != nil {
+		if env.call == nil {
+			env = env.outer
+			continue
+		}
+		trace = append(trace, TraceFrame{Function: env.call.Function, Position: pos})
+		pos = env.call.Position
+		env = env.call.Caller
+	}
+
+	return CompactTrace(append(trace, TraceFrame{Function: MainFunctionName, Position: pos}))
+}
@@ -1,7 +1,9 @@
 package data
 
 type Error struct {
-	Message string
+	Message   string
+	Exception *Exception // Set once the error is raised as an exception (ex. by `throw`)
+	Fatal     error      // Set when the error stops the program for good (ex. an exceeded limit), it can't be caught
 }
 
 func (e *Error) Type() DataType  { return ERROR_TYPE }
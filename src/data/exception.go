@@ -0,0 +1,96 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+// MainFunctionName is the name of the top-level code in stack traces
+const MainFunctionName = "<main>"
+
+// AnonymousFunctionName is the name of functions not bound by a let statement in stack traces
+const AnonymousFunctionName = "<anonymous>"
+
+// The kinds of exceptions
+const (
+	ErrorKind        = "Error"        // Thrown by the program
+	RuntimeErrorKind = "RuntimeError" // Raised by the runtime (ex. division by zero)
+)
+
+// Exception is an error value which can be thrown and caught
+type Exception struct {
+	Kind    string
+	Message string
+	Value   Data         // The thrown value (nil for exceptions raised by the runtime)
+	Trace   []TraceFrame // The calls active when the exception was raised, innermost first
+}
+
+// TraceFrame describes a call in a stack trace
+type TraceFrame struct {
+	Function string
+	Position token.Position // The position being executed by the call (if known)
+	Repeated int            // How many more times the same call follows it (ex. in a deep recursion)
+}
+
+func (e *Exception) Type() DataType  { return EXCEPTION_TYPE }
+func (e *Exception) Inspect() string { return e.Kind + ": " + e.Message }
+
+// Field returns the value of an exception field (message, kind, value or trace)
+func (e *Exception) Field(name string) (Data, bool) {
+	switch name {
+	case "message":
+		return &String{Value: e.Message}, true
+	case "kind":
+		return &String{Value: e.Kind}, true
+	case "value":
+		if e.Value == nil {
+			return NULL, true
+		}
+		return e.Value, true
+	case "trace":
+		elements := make([]Data, len(e.Trace))
+		for i, frame := range e.Trace {
+			elements[i] = &String{Value: frame.String()}
+		}
+		return NewArray(elements), true
+	default:
+		return nil, false
+	}
+}
+
+// StackTrace returns the stack trace, one call per line
+func (e *Exception) StackTrace() string {
+	lines := make([]string, len(e.Trace))
+	for i, frame := range e.Trace {
+		lines[i] = frame.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String returns the trace frame as "at name (position)"
+func (f TraceFrame) String() string {
+	str := "at " + f.Function
+	if f.Position.IsValid() {
+		str += " (" + f.Position.String() + ")"
+	}
+	if f.Repeated > 0 {
+		str += fmt.Sprintf(" [repeated %d more times]", f.Repeated)
+	}
+	return str
+}
+
+// CompactTrace merges the consecutive frames of the same call into one, so deep recursions keep short traces
+func CompactTrace(trace []TraceFrame) []TraceFrame {
+	compacted := []TraceFrame{}
+	for _, frame := range trace {
+		last := len(compacted) - 1
+		if last >= 0 && compacted[last].Function == frame.Function && compacted[last].Position == frame.Position {
+			compacted[last].Repeated += 1 + frame.Repeated
+			continue
+		}
+		compacted = append(compacted, frame)
+	}
+	return compacted
+}
@@ -0,0 +1,50 @@
+package data
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() DataType { return FLOAT_TYPE }
+
+// Inspect formats the float so it can always be told apart from an integer (ex. 2.0 instead of 2)
+func (f *Float) Inspect() string {
+	str := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if strings.ContainsAny(str, ".eIN") {
+		return str
+	}
+	return str + ".0"
+}
+
+// NumberValue returns the value of a number as a float (integers are converted), ok is false if it isn't a number
+func NumberValue(d Data) (value float64, ok bool) {
+	switch d := d.(type) {
+	case *Integer:
+		return float64(d.Value), true
+	case *Float:
+		return d.Value, true
+	default:
+		return 0, false
+	}
+}
+
+func (f *Float) Hash() uint64 {
+	// Both zeros are equal, so they must hash equally
+	if f.Value == 0 {
+		return 0
+	}
+	return math.Float64bits(f.Value)
+}
+
+// Returns the integer a float equals, integral is false if it has a fractional part or is out of the range of integers
+func (f *Float) integer() (i int64, integral bool) {
+	if f.Value != math.Trunc(f.Value) || f.Value < math.MinInt64 || f.Value >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f.Value), true
+}
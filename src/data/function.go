@@ -10,6 +10,7 @@ type Function struct {
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Name       string // The name the function was bound to (if any)
 }
 
 func (f *Function) Type() DataType { return FUNCTION_TYPE }
@@ -0,0 +1,174 @@
+package data
+
+import "math/bits"
+
+// A persistent hash array mapped trie: each level of the tree uses 5 bits of the hash of the keys to choose a child,
+// the nodes only storing their existing children (the bitmap telling which ones exist)
+// Like the vector, updates copy the nodes on the path to the key they change and share the others
+
+const (
+	hamtBits  = 5
+	hamtWidth = 1 << hamtBits
+	hamtMask  = hamtWidth - 1
+	hamtDepth = 64 // The bits of the hashes, past which the keys with equal hashes are kept in a list
+)
+
+type hamtNode struct {
+	bitmap  uint32
+	entries []hamtEntry
+}
+
+// An entry holds either a pair or a child node
+type hamtEntry struct {
+	key      HashKey
+	hash     uint64
+	pair     HashPair
+	position int // The position of the key in the order of the hash
+	node     *hamtNode
+}
+
+// Mixes both parts of a key into the hash used to place it
+func hashKeyHash(key HashKey) uint64 {
+	h := key.Value
+	for i := 0; i < len(key.Type); i++ {
+		h = (h ^ uint64(key.Type[i])) * 1099511628211
+	}
+	// The finalizer of splitmix64, spreading keys close to each other (ex. small integers) across the tree
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	return h ^ (h >> 31)
+}
+
+// Returns the slot of a hash in a node and its index in the entries
+func (n *hamtNode) slot(hash uint64, shift uint) (bit uint32, index int) {
+	bit = 1 << ((hash >> shift) & hamtMask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *hamtNode) get(key HashKey, hash uint64) (hamtEntry, bool) {
+	for shift := uint(0); n != nil; shift += hamtBits {
+		if shift >= hamtDepth {
+			for _, e := range n.entries {
+				if e.key == key {
+					return e, true
+				}
+			}
+			return hamtEntry{}, false
+		}
+
+		bit, i := n.slot(hash, shift)
+		if n.bitmap&bit == 0 {
+			return hamtEntry{}, false
+		}
+		e := n.entries[i]
+		if e.node == nil {
+			return e, e.key == key
+		}
+		n = e.node
+	}
+	return hamtEntry{}, false
+}
+
+// Returns a node with an entry set, added is false if it replaced the entry of the same key
+func (n *hamtNode) set(entry hamtEntry, shift uint) (node *hamtNode, added bool) {
+	if n == nil {
+		n = &hamtNode{}
+	}
+
+	if shift >= hamtDepth {
+		entries := make([]hamtEntry, len(n.entries), len(n.entries)+1)
+		copy(entries, n.entries)
+		for i, e := range entries {
+			if e.key == entry.key {
+				entries[i] = entry
+				return &hamtNode{entries: entries}, false
+			}
+		}
+		return &hamtNode{entries: append(entries, entry)}, true
+	}
+
+	bit, i := n.slot(entry.hash, shift)
+	if n.bitmap&bit == 0 {
+		entries := make([]hamtEntry, len(n.entries)+1)
+		copy(entries, n.entries[:i])
+		entries[i] = entry
+		copy(entries[i+1:], n.entries[i:])
+		return &hamtNode{bitmap: n.bitmap | bit, entries: entries}, true
+	}
+
+	replacement := entry
+	existing := n.entries[i]
+	switch {
+	case existing.node != nil:
+		var child *hamtNode
+		child, added = existing.node.set(entry, shift+hamtBits)
+		replacement = hamtEntry{node: child}
+	case existing.key != entry.key:
+		// Two keys share the slot, a child node holds both
+		child, _ := (&hamtNode{}).set(existing, shift+hamtBits)
+		child, _ = child.set(entry, shift+hamtBits)
+		replacement = hamtEntry{node: child}
+		added = true
+	}
+
+	entries := make([]hamtEntry, len(n.entries))
+	copy(entries, n.entries)
+	entries[i] = replacement
+	return &hamtNode{bitmap: n.bitmap, entries: entries}, added
+}
+
+// Returns a node without the entry of a key, removed is false if there is none
+func (n *hamtNode) remove(key HashKey, hash uint64, shift uint) (node *hamtNode, removed bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	if shift >= hamtDepth {
+		for i, e := range n.entries {
+			if e.key == key {
+				return &hamtNode{entries: removeEntry(n.entries, i)}, true
+			}
+		}
+		return n, false
+	}
+
+	bit, i := n.slot(hash, shift)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	e := n.entries[i]
+	if e.node == nil {
+		if e.key != key {
+			return n, false
+		}
+		return &hamtNode{bitmap: n.bitmap &^ bit, entries: removeEntry(n.entries, i)}, true
+	}
+
+	child, removed := e.node.remove(key, hash, shift+hamtBits)
+	if !removed {
+		return n, false
+	}
+	switch {
+	case len(child.entries) == 0:
+		return &hamtNode{bitmap: n.bitmap &^ bit, entries: removeEntry(n.entries, i)}, true
+	case len(child.entries) == 1 && child.entries[0].node == nil:
+		// A single pair left in the child moves up in its place
+		e = child.entries[0]
+	default:
+		e = hamtEntry{node: child}
+	}
+
+	entries := make([]hamtEntry, len(n.entries))
+	copy(entries, n.entries)
+	entries[i] = e
+	return &hamtNode{bitmap: n.bitmap, entries: entries}, true
+}
+
+func removeEntry(entries []hamtEntry, i int) []hamtEntry {
+	result := make([]hamtEntry, 0, len(entries)-1)
+	result = append(result, entries[:i]...)
+	return append(result, entries[i+1:]...)
+}
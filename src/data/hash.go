@@ -15,17 +15,28 @@ type HashPair struct {
 	Value Data
 }
 
+// Hash maps keys to values, keeping the order the keys were first inserted in
+// It is backed by persistent structures, so copies share them and updates only copy what they change
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	index   *hamtNode // The pairs, with the position of their key in order
+	count   int
+	order   vector // The keys in insertion order, deleted keys are left as nil holes until compacted
+	deleted int    // The number of holes in order
+	created int    // The pairs set since the hash was created or copied, the others being shared (counted by the budgets)
 }
 
-func (b *Hash) Type() DataType { return HASH_TYPE }
+// NewHash creates an empty hash (size is a hint of the number of pairs it will hold)
+func NewHash(size int) *Hash {
+	return &Hash{order: vectorOf(nil)}
+}
+
+func (h *Hash) Type() DataType { return HASH_TYPE }
 
 func (h *Hash) Inspect() string {
 	var sb strings.Builder
 	pairs := []string{}
 
-	for _, pair := range h.Pairs {
+	for _, pair := range h.Pairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -36,12 +47,94 @@ func (h *Hash) Inspect() string {
 	return sb.String()
 }
 
+// Len returns the number of pairs
+func (h *Hash) Len() int {
+	return h.count
+}
+
+// Get returns the pair of a key
+func (h *Hash) Get(key HashKey) (HashPair, bool) {
+	entry, ok := h.index.get(key, hashKeyHash(key))
+	return entry.pair, ok
+}
+
+// Set sets the pair of a key, an existing key keeping its position
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	hash := hashKeyHash(key)
+	entry := hamtEntry{key: key, hash: hash, pair: pair, position: h.order.count}
+	h.created++
+	if existing, ok := h.index.get(key, hash); ok {
+		entry.position = existing.position
+		h.index, _ = h.index.set(entry, 0)
+		return
+	}
+
+	h.index, _ = h.index.set(entry, 0)
+	h.order = h.order.push(pair.Key)
+	h.count++
+}
+
+// Delete removes the pair of a key, returning false if there is none
+func (h *Hash) Delete(key HashKey) bool {
+	hash := hashKeyHash(key)
+	entry, ok := h.index.get(key, hash)
+	if !ok {
+		return false
+	}
+	h.index, _ = h.index.remove(key, hash, 0)
+	h.order = h.order.set(entry.position, nil)
+	h.count--
+	h.deleted++
+
+	// Compact the order once it is mostly holes, so deletions stay O(log n) amortized
+	if h.deleted > h.order.count/2 {
+		h.compact()
+	}
+	return true
+}
+
+// Pairs returns the pairs in insertion order
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, h.count)
+	for _, key := range h.order.values(0) {
+		if key != nil {
+			pair, _ := h.Get(HashData(key.(HashableData)))
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// Copy returns a new hash with the same pairs, in the same order (shared with this hash)
+func (h *Hash) Copy() *Hash {
+	result := *h
+	result.created = 0
+	return &result
+}
+
+func (h *Hash) compact() {
+	pairs := h.Pairs()
+	created := h.created
+	*h = Hash{order: vectorOf(nil)}
+	for _, pair := range pairs {
+		h.Set(HashData(pair.Key.(HashableData)), pair)
+	}
+	h.created += created
+}
+
 // Interface
 type HashableData interface {
 	Data
 	Hash() uint64
 }
 
+// HashData returns the key of a value in a hash
+// An integral float has the key of the integer it equals, so they find the same pair
 func HashData(h HashableData) HashKey {
+	if f, ok := h.(*Float); ok {
+		if i, integral := f.integer(); integral {
+			return HashKey{Type: INTEGER_TYPE, Value: uint64(i)}
+		}
+	}
 	return HashKey{Type: h.Type(), Value: h.Hash()}
 }
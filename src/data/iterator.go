@@ -0,0 +1,78 @@
+package data
+
+import (
+	"unicode/utf8"
+)
+
+// Iterator walks over the values of an iterable, one at a time
+type Iterator struct {
+	next func() (Data, bool)
+}
+
+func (i *Iterator) Type() DataType  { return ITERATOR_TYPE }
+func (i *Iterator) Inspect() string { return "iterator" }
+
+// Next returns the next value, or false once the iterator is exhausted
+func (i *Iterator) Next() (Data, bool) {
+	return i.next()
+}
+
+// NewIterator creates an iterator over array elements, hash keys, string characters or range integers
+func NewIterator(d Data) (*Iterator, bool) {
+	switch d := d.(type) {
+	case *Array:
+		array, index := *d, 0 // The elements when the iteration starts
+		return &Iterator{next: func() (Data, bool) {
+			if index >= array.Len() {
+				return nil, false
+			}
+			index++
+			return array.Get(index - 1), true
+		}}, true
+
+	case *Hash:
+		pairs := d.Pairs()
+		keys := make([]Data, len(pairs))
+		for i, pair := range pairs {
+			keys[i] = pair.Key
+		}
+		return iterateSlice(keys), true
+
+	case *String:
+		str := d.Value
+		return &Iterator{next: func() (Data, bool) {
+			if len(str) == 0 {
+				return nil, false
+			}
+			char, width := utf8.DecodeRuneInString(str)
+			str = str[width:]
+			return &String{Value: string(char)}, true
+		}}, true
+
+	case *Range:
+		current, remaining := d.Start, d.Len()
+		return &Iterator{next: func() (Data, bool) {
+			if remaining == 0 {
+				return nil, false
+			}
+			value := current
+			current += d.Step
+			remaining--
+			return &Integer{Value: value}, true
+		}}, true
+
+	default:
+		return nil, false
+	}
+}
+
+func iterateSlice(elements []Data) *Iterator {
+	index := 0
+	return &Iterator{next: func() (Data, bool) {
+		if index >= len(elements) {
+			return nil, false
+		}
+		index++
+		return elements[index-1], true
+	}}
+}
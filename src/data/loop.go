@@ -0,0 +1,13 @@
+package data
+
+// Break signals the enclosing loop to stop (used by the interpreter)
+type Break struct{}
+
+func (b *Break) Type() DataType  { return BREAK_TYPE }
+func (b *Break) Inspect() string { return "break" }
+
+// Continue signals the enclosing loop to skip to its next iteration (used by the interpreter)
+type Continue struct{}
+
+func (c *Continue) Type() DataType  { return CONTINUE_TYPE }
+func (c *Continue) Inspect() string { return "continue" }
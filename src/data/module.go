@@ -0,0 +1,48 @@
+package data
+
+import "github.com/ape-lang/ape/src/ast"
+
+// Module is the namespace holding the values exported by a module
+type Module struct {
+	Name    string // The path the module was first imported with
+	Exports map[string]Data
+}
+
+func (m *Module) Type() DataType  { return MODULE_TYPE }
+func (m *Module) Inspect() string { return "module(" + m.Name + ")" }
+
+// Export returns the value exported under the given name
+func (m *Module) Export(name string) (Data, bool) {
+	value, ok := m.Exports[name]
+	return value, ok
+}
+
+// ModuleLoader finds and parses the source files of modules (ex. a *module.Loader)
+type ModuleLoader interface {
+	Resolve(name string, from string) (string, error) // Returns the file path of an imported module
+	Enter(file string) error                          // Starts loading a module, failing on import cycles
+	Leave()                                           // Ends loading the module last entered
+	Parse(file string) (*ast.Program, error)
+}
+
+// Imports holds the modules imported by a program, shared by the environments of its modules
+type Imports struct {
+	Loader  ModuleLoader
+	modules map[string]*Module // The loaded modules by file path
+}
+
+// NewImports creates an empty set of imports, finding the modules with the given loader
+func NewImports(loader ModuleLoader) *Imports {
+	return &Imports{Loader: loader, modules: make(map[string]*Module)}
+}
+
+// Module returns the module loaded from the given file (if any)
+func (i *Imports) Module(file string) (*Module, bool) {
+	m, ok := i.modules[file]
+	return m, ok
+}
+
+// SetModule stores the module loaded from the given file
+func (i *Imports) SetModule(file string, m *Module) {
+	i.modules[file] = m
+}
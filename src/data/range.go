@@ -0,0 +1,27 @@
+package data
+
+import "fmt"
+
+// Range is a lazy sequence of integers from Start (inclusive) to End (exclusive)
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() DataType { return RANGE_TYPE }
+
+func (r *Range) Inspect() string {
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
+// Len returns the number of integers in the range
+func (r *Range) Len() int64 {
+	if r.Step > 0 && r.Start < r.End {
+		return (r.End - r.Start + r.Step - 1) / r.Step
+	}
+	if r.Step < 0 && r.Start > r.End {
+		return (r.Start - r.End - r.Step - 1) / -r.Step
+	}
+	return 0
+}
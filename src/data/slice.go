@@ -0,0 +1,46 @@
+package data
+
+import "fmt"
+
+// Slice returns the part of a string (counting characters, not bytes) or an array from start to end (excluded)
+// A null bound stands for the beginning or the end, the bounds are clamped to the length and an end before the start
+// gives an empty part
+func Slice(d, start, end Data) (Data, error) {
+	var length int
+	var runes []rune
+	switch d := d.(type) {
+	case *String:
+		runes = []rune(d.Value)
+		length = len(runes)
+	case *Array:
+		length = d.Len()
+	default:
+		return nil, fmt.Errorf("slice operator not supported: %s", d.Type())
+	}
+
+	from, err := sliceBound(start, 0, length)
+	if err != nil {
+		return nil, err
+	}
+	to, err := sliceBound(end, length, length)
+	if err != nil {
+		return nil, err
+	}
+	to = max(from, to)
+
+	if array, ok := d.(*Array); ok {
+		return NewArray(array.Elements()[from:to]), nil
+	}
+	return &String{Value: string(runes[from:to])}, nil
+}
+
+func sliceBound(bound Data, missing, length int) (int, error) {
+	switch bound := bound.(type) {
+	case *Null:
+		return missing, nil
+	case *Integer:
+		return int(min(max(bound.Value, 0), int64(length))), nil
+	default:
+		return 0, fmt.Errorf("slice bounds must be INTEGER, got %s", bound.Type())
+	}
+}
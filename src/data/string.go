@@ -15,3 +15,17 @@ func (s *String) Hash() uint64 {
 	h.Write([]byte(s.Value))
 	return h.Sum64()
 }
+
+// CharAt returns the character at an index (counting characters, not bytes), or null if it is out of range
+func (s *String) CharAt(i int64) Data {
+	if i < 0 {
+		return NULL
+	}
+	for _, char := range s.Value {
+		if i == 0 {
+			return &String{Value: string(char)}
+		}
+		i--
+	}
+	return NULL
+}
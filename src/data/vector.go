@@ -0,0 +1,164 @@
+package data
+
+// A persistent vector: the values are stored in the leaves of a tree whose nodes have up to 32 children, except the
+// last ones (up to 32) kept in a tail until it is full and moved into the tree
+// Updates copy the nodes on the path to the value they change and share the others, so they take O(log32 n) and the
+// previous version stays unchanged
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+type vectorNode struct {
+	children []*vectorNode // For the inner nodes
+	values   []Data        // For the leaves
+}
+
+type vector struct {
+	count int
+	shift uint // The bits of an index used below the root
+	root  *vectorNode
+	tail  []Data // Holds 1 to 32 values, unless the vector is empty
+}
+
+// Creates a vector holding the given values (the slice is kept, so it must not be changed afterwards)
+func vectorOf(values []Data) vector {
+	v := vector{shift: vectorBits, root: &vectorNode{}}
+	if len(values) == 0 {
+		return v
+	}
+
+	tailOffset := (len(values) - 1) &^ vectorMask
+	for offset := 0; offset < tailOffset; offset += vectorWidth {
+		v.count = offset + vectorWidth
+		v.pushLeaf(&vectorNode{values: values[offset : offset+vectorWidth : offset+vectorWidth]})
+	}
+	v.tail = values[tailOffset:len(values):len(values)]
+	v.count = len(values)
+	return v
+}
+
+// The index of the first value in the tail
+func (v vector) tailOffset() int {
+	return v.count - len(v.tail)
+}
+
+func (v vector) get(i int) Data {
+	if i >= v.tailOffset() {
+		return v.tail[i-v.tailOffset()]
+	}
+	return v.leaf(i).values[i&vectorMask]
+}
+
+// Returns the leaf holding an index (outside the tail)
+func (v vector) leaf(i int) *vectorNode {
+	node := v.root
+	for level := v.shift; level > 0; level -= vectorBits {
+		node = node.children[(i>>level)&vectorMask]
+	}
+	return node
+}
+
+// Returns a vector with the value at an index replaced
+func (v vector) set(i int, d Data) vector {
+	if i >= v.tailOffset() {
+		tail := make([]Data, len(v.tail))
+		copy(tail, v.tail)
+		tail[i-v.tailOffset()] = d
+		v.tail = tail
+		return v
+	}
+	v.root = setValue(v.root, v.shift, i, d)
+	return v
+}
+
+func setValue(node *vectorNode, level uint, i int, d Data) *vectorNode {
+	if level == 0 {
+		values := make([]Data, len(node.values))
+		copy(values, node.values)
+		values[i&vectorMask] = d
+		return &vectorNode{values: values}
+	}
+
+	children := make([]*vectorNode, len(node.children))
+	copy(children, node.children)
+	j := (i >> level) & vectorMask
+	children[j] = setValue(children[j], level-vectorBits, i, d)
+	return &vectorNode{children: children}
+}
+
+// Returns a vector with a value added at the end
+func (v vector) push(d Data) vector {
+	if v.root == nil {
+		v = vectorOf(nil)
+	}
+
+	if len(v.tail) < vectorWidth {
+		tail := make([]Data, len(v.tail)+1)
+		copy(tail, v.tail)
+		tail[len(v.tail)] = d
+		v.tail = tail
+		v.count++
+		return v
+	}
+
+	v.pushLeaf(&vectorNode{values: v.tail})
+	v.tail = []Data{d}
+	v.count++
+	return v
+}
+
+// Moves a full leaf into the tree, count already including its values
+func (v *vector) pushLeaf(leaf *vectorNode) {
+	// The root is full, it becomes the first child of a new root
+	if (v.count >> vectorBits) > (1 << v.shift) {
+		v.root = &vectorNode{children: []*vectorNode{v.root, newPath(v.shift, leaf)}}
+		v.shift += vectorBits
+		return
+	}
+	v.root = insertLeaf(v.root, v.shift, v.count-1, leaf)
+}
+
+func insertLeaf(node *vectorNode, level uint, i int, leaf *vectorNode) *vectorNode {
+	j := (i >> level) & vectorMask
+	children := make([]*vectorNode, max(len(node.children), j+1))
+	copy(children, node.children)
+
+	switch {
+	case level == vectorBits:
+		children[j] = leaf
+	case j < len(node.children):
+		children[j] = insertLeaf(node.children[j], level-vectorBits, i, leaf)
+	default:
+		children[j] = newPath(level-vectorBits, leaf)
+	}
+	return &vectorNode{children: children}
+}
+
+// Creates the nodes leading to a leaf from a given level
+func newPath(level uint, leaf *vectorNode) *vectorNode {
+	if level == 0 {
+		return leaf
+	}
+	return &vectorNode{children: []*vectorNode{newPath(level-vectorBits, leaf)}}
+}
+
+// Returns the values from an index to the end, in a new slice
+func (v vector) values(from int) []Data {
+	values := make([]Data, 0, max(v.count-from, 0))
+	for i := from &^ vectorMask; i < v.tailOffset(); i += vectorWidth {
+		leaf := v.leaf(i).values
+		if i < from {
+			leaf = leaf[from-i:]
+		}
+		values = append(values, leaf...)
+	}
+
+	tail := v.tail
+	if from > v.tailOffset() {
+		tail = tail[from-v.tailOffset():]
+	}
+	return append(values, tail...)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/ape-lang/ape/src/compiler/compiler"
+)
+
+// disasm prints a listing of the bytecode of a script file (or a compiled file)
+func disasm(path string, optimization int) int {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read file:\n Error: %s\n", err)
+		return 1
+	}
+
+	var bytecode *compiler.Bytecode
+	if bytes.HasPrefix(source, []byte(compiler.Magic)) {
+		bytecode = &compiler.Bytecode{}
+		err = bytecode.UnmarshalBinary(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not load compiled file:\n Error: %s\n", err)
+			return 1
+		}
+	} else {
+		var ok bool
+		bytecode, ok = compile(path, string(source), optimization)
+		if !ok {
+			return 1
+		}
+	}
+
+	// The positions name the files the instructions were compiled from (including imported modules)
+	bytecode.Disassemble(os.Stdout, func(file string) (string, bool) {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", false
+		}
+		return string(content), true
+	})
+	return 0
+}
@@ -6,6 +6,36 @@ import (
 )
 
 func Eval(node ast.Node, env *data.Environment) data.Data {
+	budget := env.Budget()
+	if budget == nil {
+		return traced(eval(node, env), node, env)
+	}
+
+	if err := evalStep(budget); err != nil {
+		return err
+	}
+	result := traced(eval(node, env), node, env)
+	if allocates(node) {
+		return evalAllocation(result, budget)
+	}
+	return result
+}
+
+// Errors are raised as exceptions, recording the stack trace where they first appear
+func traced(result data.Data, node ast.Node, env *data.Environment) data.Data {
+	if err, ok := result.(*data.Error); ok {
+		if err.Exception == nil {
+			err.Exception = &data.Exception{Kind: data.RuntimeErrorKind, Message: err.Message}
+		}
+		if err.Exception.Trace == nil {
+			err.Exception.Trace = env.Trace(node.Position())
+		}
+	}
+
+	return result
+}
+
+func eval(node ast.Node, env *data.Environment) data.Data {
 	switch node := node.(type) {
 
 	// Evaluate statements
@@ -29,6 +59,27 @@ func Eval(node ast.Node, env *data.Environment) data.Data {
 		}
 		return evalReturn(data)
 
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.BreakStatement:
+		return data.BREAK
+
+	case *ast.ContinueStatement:
+		return data.CONTINUE
+
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
+
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+
+	case *ast.ExportStatement:
+		return Eval(node.Let, env)
+
 	// Evaluate expressions
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
@@ -41,6 +92,10 @@ func Eval(node ast.Node, env *data.Environment) data.Data {
 		return evalPrefixExpression(node.Operator, right)
 
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
+
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -59,6 +114,8 @@ func Eval(node ast.Node, env *data.Environment) data.Data {
 		// Evaluate expressions
 	case *ast.IntegerLiteral:
 		return evalInteger(node.Value)
+	case *ast.FloatLiteral:
+		return evalFloat(node.Value)
 
 	case *ast.StringLiteral:
 		return evalString(node.Value)
@@ -83,7 +140,7 @@ func Eval(node ast.Node, env *data.Environment) data.Data {
 			return args[0]
 		}
 
-		return evalCallResult(fn, args)
+		return evalCallResult(fn, args, env, node.Position())
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
@@ -109,6 +166,19 @@ func Eval(node ast.Node, env *data.Environment) data.Data {
 		}
 
 		return evalIndexExpression(left, index)
+
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+
+	case *ast.MemberExpression:
+		object := Eval(node.Object, env)
+		if isError(object) {
+			return object
+		}
+		return evalIndexExpression(object, &data.String{Value: node.Member.Value})
+
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
 	}
 
 	return nil
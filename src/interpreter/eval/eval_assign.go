@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+)
+
+func evalAssignExpression(ae *ast.AssignExpression, env *data.Environment) data.Data {
+	switch target := ae.Target.(type) {
+	case *ast.Identifier:
+		var current data.Data
+		if ae.Operator != "=" {
+			current = evalIdentifier(target, env)
+			if isError(current) {
+				return current
+			}
+		}
+
+		value := Eval(ae.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		if current != nil {
+			value = evalInfixExpression(ae.BinaryOperator(), current, value)
+			if isError(value) {
+				return value
+			}
+		}
+
+		if !env.Assign(target.Value, value) {
+			return evalError("Identifier not found: %s", target.Value)
+		}
+		return value
+
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		value := Eval(ae.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		if ae.Operator != "=" {
+			current := evalIndexExpression(left, index)
+			if isError(current) {
+				return current
+			}
+			value = evalInfixExpression(ae.BinaryOperator(), current, value)
+			if isError(value) {
+				return value
+			}
+		}
+
+		return evalSetIndex(left, index, value)
+
+	default:
+		return evalError("Invalid assignment target: %s", ae.Target.String())
+	}
+}
+
+func evalSetIndex(left, index, value data.Data) data.Data {
+	switch left := left.(type) {
+	case *data.Array:
+		i, ok := index.(*data.Integer)
+		if !ok {
+			return evalError("array index must be INTEGER, got %s", index.Type())
+		}
+		if i.Value < 0 || i.Value >= int64(left.Len()) {
+			return evalError("array index out of range: %d", i.Value)
+		}
+		left.Set(int(i.Value), value)
+		return value
+
+	case *data.Hash:
+		key, ok := index.(data.HashableData)
+		if !ok {
+			return evalError("unusable as hash key: %s", index.Type())
+		}
+		left.Set(data.HashData(key), data.HashPair{Key: index, Value: value})
+		return value
+
+	default:
+		return evalError("index assignment not supported: %s", left.Type())
+	}
+}
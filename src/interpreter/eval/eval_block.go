@@ -14,7 +14,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *data.Environment) data.D
 		if result != nil {
 			rt := result.Type()
 
-			if rt == data.RETURN_TYPE || rt == data.ERROR_TYPE {
+			if rt == data.RETURN_TYPE || rt == data.ERROR_TYPE || rt == data.BREAK_TYPE || rt == data.CONTINUE_TYPE {
 				return result
 			}
 		}
@@ -0,0 +1,54 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+)
+
+// EvalContext evaluates a node like Eval, stopping once the context is done or the limits are exceeded
+// The returned error is then a *data.CanceledError, *data.StepLimitError or *data.AllocationLimitError
+func EvalContext(ctx context.Context, node ast.Node, env *data.Environment, limits data.Limits) (data.Data, error) {
+	previous := env.Budget()
+	env.SetBudget(data.NewBudget(ctx, limits))
+	defer env.SetBudget(previous)
+
+	result := Eval(node, env)
+	if err, ok := result.(*data.Error); ok && err.Fatal != nil {
+		return nil, err.Fatal
+	}
+	return result, nil
+}
+
+// Counts a step of the evaluation (one per node evaluated)
+func evalStep(budget *data.Budget) *data.Error {
+	if err := budget.Step(); err != nil {
+		return fatalError(err)
+	}
+	return nil
+}
+
+// Counts the values created by a node (the results of operators, literals of collections and functions, and builtin calls)
+func evalAllocation(result data.Data, budget *data.Budget) data.Data {
+	if budget == nil || isError(result) {
+		return result
+	}
+	if err := budget.Allocate(result); err != nil {
+		return fatalError(err)
+	}
+	return result
+}
+
+func allocates(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.PrefixExpression, *ast.InfixExpression, *ast.ArrayLiteral, *ast.HashLiteral, *ast.FunctionLiteral, *ast.SliceExpression:
+		return true
+	}
+	return false
+}
+
+// An error stopping the evaluation for good, which try statements don't catch
+func fatalError(err error) *data.Error {
+	return &data.Error{Message: err.Error(), Fatal: err}
+}
@@ -4,13 +4,13 @@ import (
 	"github.com/ape-lang/ape/src/data"
 )
 
-var builtins = map[string]*data.Builtin{
-	"len":   data.GetBuiltinDef("len"),
-	"head":  data.GetBuiltinDef("head"),
-	"tail":  data.GetBuiltinDef("tail"),
-	"last":  data.GetBuiltinDef("last"),
-	"push":  data.GetBuiltinDef("push"),
-	"print": data.GetBuiltinDef("print"),
+// The builtins by name, the same as the VM's
+var builtins = map[string]*data.Builtin{}
+
+func init() {
+	for _, b := range data.Builtins {
+		builtins[b.Name] = b.Definition
+	}
 }
 
 func evalBuiltin(value string) (*data.Builtin, bool) {
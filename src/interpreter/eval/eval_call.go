@@ -2,25 +2,74 @@ package eval
 
 import (
 	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/token"
 )
 
-func evalCallResult(function data.Data, args []data.Data) data.Data {
+func evalCallResult(function data.Data, args []data.Data, caller *data.Environment, pos token.Position) data.Data {
 	switch fn := function.(type) {
 	case *data.Function:
-		closure := evalCallClosure(fn, args)
-		result := Eval(fn.Body, closure)
-		return evalCallReturn(result)
+		for {
+			if len(args) != len(fn.Parameters) {
+				return evalError("wrong number of arguments: want=%d, got=%d", len(fn.Parameters), len(args))
+			}
+
+			call, err := evalCallInfo(fn.Name, pos, caller)
+			if err != nil {
+				return err
+			}
+			if call.Function == "" {
+				call.Function = data.AnonymousFunctionName
+			}
+			closure := evalCallClosure(fn, args, call)
+			result := evalCallReturn(evalLoopEscape(evalTail(fn.Body, closure)))
+
+			// A call in tail position replaces this one (keeping its caller), instead of nesting in it
+			next, ok := result.(*tailCall)
+			if !ok {
+				return result
+			}
+			fn, args = next.function, next.args
+		}
 
 	case *data.Builtin:
-		return fn.Fn(args...)
+		budget := caller.Budget()
+		if err := fn.Reserve(budget, args); err != nil {
+			return fatalError(err)
+		}
+		return evalAllocation(fn.Call(&evalCaller{env: caller, pos: pos}, args...), budget)
 
 	default:
 		return evalError("not a function: %s", fn.Type())
 	}
 }
 
-func evalCallClosure(fn *data.Function, args []data.Data) *data.Environment {
-	env := data.NewEnvironmentClosure(fn.Env)
+// Creates the information of a call (or module import), raising a stack overflow past the depth limit
+func evalCallInfo(function string, pos token.Position, caller *data.Environment) (*data.CallInfo, *data.Error) {
+	budget := caller.Budget()
+	limit := int64(data.DefaultCallDepth)
+	if budget != nil && budget.Limits().CallDepth > 0 {
+		limit = budget.Limits().CallDepth
+	}
+
+	depth := caller.Depth() + 1
+	if int64(depth) > limit {
+		return nil, evalError("stack overflow")
+	}
+	return &data.CallInfo{Function: function, Position: pos, Caller: caller, Depth: depth, Budget: budget}, nil
+}
+
+// evalCaller calls functions for the builtins, as if they were called where the builtin is
+type evalCaller struct {
+	env *data.Environment
+	pos token.Position
+}
+
+func (c *evalCaller) Call(fn data.Data, args ...data.Data) data.Data {
+	return evalCallResult(fn, args, c.env, c.pos)
+}
+
+func evalCallClosure(fn *data.Function, args []data.Data, call *data.CallInfo) *data.Environment {
+	env := data.NewCallEnvironment(fn.Env, call)
 	for i, param := range fn.Parameters {
 		env.Set(param.Value, args[i])
 	}
@@ -0,0 +1,14 @@
+package eval
+
+import "github.com/ape-lang/ape/src/data"
+
+func evalFloat(value float64) *data.Float {
+	return &data.Float{Value: value}
+}
+
+// Checks if both values are numbers (integers or floats)
+func areNumbers(left, right data.Data) bool {
+	_, leftIsNumber := data.NumberValue(left)
+	_, rightIsNumber := data.NumberValue(right)
+	return leftIsNumber && rightIsNumber
+}
@@ -9,5 +9,5 @@ func evalFunction(node *ast.FunctionLiteral, env *data.Environment) *data.Functi
 	params := node.Parameters
 	body := node.Body
 
-	return &data.Function{Parameters: params, Env: env, Body: body}
+	return &data.Function{Parameters: params, Env: env, Body: body, Name: node.Name}
 }
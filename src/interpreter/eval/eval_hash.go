@@ -6,10 +6,10 @@ import (
 )
 
 func evalHashLiteral(node *ast.HashLiteral, env *data.Environment) data.Data {
-	pairs := make(map[data.HashKey]data.HashPair)
+	hash := data.NewHash(len(node.Pairs))
 
-	for keyNode, valueNode := range node.Pairs {
-		key := Eval(keyNode, env)
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
 		if isError(key) {
 			return key
 		}
@@ -19,14 +19,13 @@ func evalHashLiteral(node *ast.HashLiteral, env *data.Environment) data.Data {
 			return evalError("unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env)
+		value := Eval(pair.Value, env)
 		if isError(value) {
 			return value
 		}
 
-		hashed := data.HashData(hashKey)
-		pairs[hashed] = data.HashPair{Key: key, Value: value}
+		hash.Set(data.HashData(hashKey), data.HashPair{Key: key, Value: value})
 	}
 
-	return &data.Hash{Pairs: pairs}
+	return hash
 }
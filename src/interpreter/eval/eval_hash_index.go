@@ -10,7 +10,7 @@ func evalHashIndexExpression(hash, index data.Data) data.Data {
 		return evalError("unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashData.Pairs[data.HashData(key)]
+	pair, ok := hashData.Get(data.HashData(key))
 	if !ok {
 		return data.NULL
 	}
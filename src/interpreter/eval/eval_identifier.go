@@ -14,5 +14,5 @@ func evalIdentifier(node *ast.Identifier, env *data.Environment) data.Data {
 		return builtin
 	}
 
-	return evalError("Identifier not found: " + node.Value)
+	return evalError("Identifier not found: %s", node.Value)
 }
@@ -12,7 +12,7 @@ func evalIfExpression(ie *ast.IfExpression, env *data.Environment) data.Data {
 		return condition
 	}
 
-	if isTruthy(condition) {
+	if data.IsTruthy(condition) {
 		return Eval(ie.Consequent, env)
 	} else if ie.Alternate != nil {
 		return Eval(ie.Alternate, env)
@@ -20,16 +20,3 @@ func evalIfExpression(ie *ast.IfExpression, env *data.Environment) data.Data {
 		return data.NULL
 	}
 }
-
-func isTruthy(d data.Data) bool {
-	switch d {
-	case data.NULL:
-		return false
-	case data.TRUE:
-		return true
-	case data.FALSE:
-		return false
-	default:
-		return true
-	}
-}
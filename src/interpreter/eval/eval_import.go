@@ -0,0 +1,93 @@
+package eval
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/module"
+)
+
+func evalImportStatement(is *ast.ImportStatement, env *data.Environment) data.Data {
+	result := evalModule(is, env)
+	if isError(result) {
+		return result
+	}
+	mod := result.(*data.Module)
+
+	if is.Name != nil {
+		env.Set(is.Name.Value, mod)
+		return nil
+	}
+
+	for _, name := range is.Names {
+		value, ok := mod.Export(name.Value)
+		if !ok {
+			return evalError("module %s has no export %s", mod.Name, name.Value)
+		}
+		env.Set(name.Value, value)
+	}
+	return nil
+}
+
+// Returns the imports of the program, finding the modules in the default path unless the program set its own imports
+func evalImports(env *data.Environment) *data.Imports {
+	imports := env.Imports()
+	if imports == nil {
+		imports = data.NewImports(module.NewLoader(module.DefaultPath()))
+		env.SetImports(imports)
+	}
+	return imports
+}
+
+// Evaluates an imported module in its own environment, only on the first import
+func evalModule(is *ast.ImportStatement, env *data.Environment) data.Data {
+	imports := evalImports(env)
+
+	file, err := imports.Loader.Resolve(is.Path, is.Position().File)
+	if err != nil {
+		return evalError("%s", err)
+	}
+	if mod, ok := imports.Module(file); ok {
+		return mod
+	}
+
+	err = imports.Loader.Enter(file)
+	if err != nil {
+		return evalError("%s", err)
+	}
+	defer imports.Loader.Leave()
+
+	program, err := imports.Loader.Parse(file)
+	if err != nil {
+		return evalError("%s", err)
+	}
+
+	call, callErr := evalCallInfo("<module "+is.Path+">", is.Position(), env)
+	if callErr != nil {
+		return callErr
+	}
+	moduleEnv := data.NewModuleEnvironment(imports, call)
+	result := Eval(program, moduleEnv)
+	if isError(result) {
+		return result
+	}
+
+	exports := make(map[string]data.Data)
+	for _, name := range module.Exports(program) {
+		exports[name], _ = moduleEnv.Get(name)
+	}
+
+	mod := &data.Module{Name: is.Path, Exports: exports}
+	imports.SetModule(file, mod)
+	return mod
+}
+
+func evalModuleIndexExpression(module, index data.Data) data.Data {
+	mod := module.(*data.Module)
+	name := index.(*data.String).Value
+
+	value, ok := mod.Export(name)
+	if !ok {
+		return evalError("module %s has no export %s", mod.Name, name)
+	}
+	return value
+}
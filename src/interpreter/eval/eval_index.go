@@ -6,8 +6,14 @@ func evalIndexExpression(left, index data.Data) data.Data {
 	switch {
 	case left.Type() == data.ARRAY_TYPE && index.Type() == data.INTEGER_TYPE:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == data.STRING_TYPE && index.Type() == data.INTEGER_TYPE:
+		return left.(*data.String).CharAt(index.(*data.Integer).Value)
 	case left.Type() == data.HASH_TYPE:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == data.EXCEPTION_TYPE && index.Type() == data.STRING_TYPE:
+		return evalExceptionIndexExpression(left, index)
+	case left.Type() == data.MODULE_TYPE && index.Type() == data.STRING_TYPE:
+		return evalModuleIndexExpression(left, index)
 	default:
 		return evalError("index operator not supported: %s", left.Type())
 	}
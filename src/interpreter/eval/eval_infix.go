@@ -7,8 +7,13 @@ func evalInfixExpression(
 	left, right data.Data,
 ) data.Data {
 	switch {
+	case isComparison(operator):
+		return evalComparison(operator, left, right)
+
 	case left.Type() == data.INTEGER_TYPE && right.Type() == data.INTEGER_TYPE:
 		return evalIntegerInfixExpression(operator, left, right)
+	case areNumbers(left, right):
+		return evalFloatInfixExpression(operator, left, right)
 
 	case left.Type() == data.STRING_TYPE && right.Type() == data.STRING_TYPE:
 		return evalStringInfixExpression(operator, left, right)
@@ -16,13 +21,28 @@ func evalInfixExpression(
 	case left.Type() != right.Type():
 		return evalError("Type mismatch: %s %s %s", left.Type(), operator, right.Type())
 
-	case operator == "==":
-		return evalBoolean(left == right)
-
-	case operator == "!=":
-		return evalBoolean(left != right)
-
 	default:
 		return evalError("Unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
+
+func isComparison(operator string) bool {
+	switch operator {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// Evaluates comparisons the way the VM does (see data.Comparison)
+func evalComparison(operator string, left, right data.Data) data.Data {
+	result, ok := data.Comparison(operator, left, right)
+	if ok {
+		return evalBoolean(result)
+	}
+
+	if left.Type() != right.Type() && !areNumbers(left, right) {
+		return evalError("Type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return evalError("Unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
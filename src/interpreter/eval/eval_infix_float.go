@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"math"
+
+	"github.com/ape-lang/ape/src/data"
+)
+
+// Evaluates infix expressions between floats, or between a float and an integer
+func evalFloatInfixExpression(
+	operator string,
+	left, right data.Data,
+) data.Data {
+	leftVal, _ := data.NumberValue(left)
+	rightVal, _ := data.NumberValue(right)
+
+	switch operator {
+	case "+":
+		return &data.Float{Value: leftVal + rightVal}
+	case "-":
+		return &data.Float{Value: leftVal - rightVal}
+	case "*":
+		return &data.Float{Value: leftVal * rightVal}
+	case "/":
+		return &data.Float{Value: leftVal / rightVal}
+	case "%":
+		return &data.Float{Value: math.Mod(leftVal, rightVal)}
+	default:
+		return evalError("Unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
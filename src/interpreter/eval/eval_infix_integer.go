@@ -20,19 +20,28 @@ func evalIntegerInfixExpression(
 		return &data.Integer{Value: leftVal * rightVal}
 
 	case "/":
+		if rightVal == 0 {
+			return evalError("division by zero")
+		}
 		return &data.Integer{Value: leftVal / rightVal}
 
-	case "<":
-		return evalBoolean(leftVal < rightVal)
+	case "%":
+		if rightVal == 0 {
+			return evalError("division by zero")
+		}
+		return &data.Integer{Value: leftVal % rightVal}
 
-	case ">":
-		return evalBoolean(leftVal > rightVal)
+	case "&":
+		return &data.Integer{Value: leftVal & rightVal}
 
-	case "==":
-		return evalBoolean(leftVal == rightVal)
+	case "|":
+		return &data.Integer{Value: leftVal | rightVal}
 
-	case "!=":
-		return evalBoolean(leftVal != rightVal)
+	case "^":
+		return &data.Integer{Value: leftVal ^ rightVal}
+
+	case "<<", ">>":
+		return evalShiftExpression(operator, leftVal, rightVal)
 
 	default:
 		return evalError("Unknown operator: %s %s %s", left.Type(), operator, right.Type())
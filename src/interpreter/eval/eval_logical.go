@@ -0,0 +1,27 @@
+package eval
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+)
+
+// Evaluates `&&` and `||`, only evaluating the right node when the left one doesn't decide the result
+func evalLogicalExpression(ie *ast.InfixExpression, env *data.Environment) data.Data {
+	left := Eval(ie.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	if ie.Operator == "&&" && !data.IsTruthy(left) {
+		return data.FALSE
+	}
+	if ie.Operator == "||" && data.IsTruthy(left) {
+		return data.TRUE
+	}
+
+	right := Eval(ie.Right, env)
+	if isError(right) {
+		return right
+	}
+	return evalBoolean(data.IsTruthy(right))
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+)
+
+func evalWhileStatement(ws *ast.WhileStatement, env *data.Environment) data.Data {
+	for {
+		condition := Eval(ws.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !data.IsTruthy(condition) {
+			return data.NULL
+		}
+
+		if result, stop := evalLoopBody(ws.Body, env); stop {
+			return result
+		}
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, env *data.Environment) data.Data {
+	iterable := Eval(fs.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	iterator, ok := data.NewIterator(iterable)
+	if !ok {
+		return evalError("Not iterable: %s", iterable.Type())
+	}
+
+	for {
+		value, ok := iterator.Next()
+		if !ok {
+			return data.NULL
+		}
+		env.Set(fs.Name.Value, value)
+
+		if result, stop := evalLoopBody(fs.Body, env); stop {
+			return result
+		}
+	}
+}
+
+// Evaluates the body of a loop, returning whether the loop should stop (and with which result)
+func evalLoopBody(body *ast.BlockStatement, env *data.Environment) (data.Data, bool) {
+	switch result := Eval(body, env).(type) {
+	case *data.Break:
+		return data.NULL, true
+	case *data.Return, *data.Error:
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// Converts a break or continue which escaped its loop into an error
+func evalLoopEscape(d data.Data) data.Data {
+	switch d.(type) {
+	case *data.Break:
+		return evalError("break outside of a loop")
+	case *data.Continue:
+		return evalError("continue outside of a loop")
+	default:
+		return d
+	}
+}
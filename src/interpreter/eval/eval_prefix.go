@@ -10,6 +10,8 @@ func evalPrefixExpression(operator string, right data.Data) data.Data {
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalBitNotPrefixOperatorExpression(right)
 	default:
 		return evalError("Unknown operator: %s%s", operator, right.Type())
 	}
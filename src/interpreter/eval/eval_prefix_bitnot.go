@@ -0,0 +1,11 @@
+package eval
+
+import "github.com/ape-lang/ape/src/data"
+
+func evalBitNotPrefixOperatorExpression(right data.Data) data.Data {
+	integer, ok := right.(*data.Integer)
+	if !ok {
+		return evalError("Unknown operator: ~%s", right.Type())
+	}
+	return &data.Integer{Value: ^integer.Value}
+}
@@ -3,10 +3,12 @@ package eval
 import "github.com/ape-lang/ape/src/data"
 
 func evalMinusPrefixOperatorExpression(right data.Data) data.Data {
-	if right.Type() != data.INTEGER_TYPE {
+	switch right := right.(type) {
+	case *data.Integer:
+		return &data.Integer{Value: -right.Value}
+	case *data.Float:
+		return &data.Float{Value: -right.Value}
+	default:
 		return evalError("Unknown operator: -%s", right.Type())
 	}
-
-	value := right.(*data.Integer).Value
-	return &data.Integer{Value: -value}
 }
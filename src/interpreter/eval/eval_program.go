@@ -9,7 +9,7 @@ func evalProgram(program *ast.Program, env *data.Environment) data.Data {
 	var result data.Data
 
 	for _, statement := range program.Statements {
-		result = Eval(statement, env)
+		result = evalLoopEscape(Eval(statement, env))
 
 		switch result := result.(type) {
 		case *data.Return:
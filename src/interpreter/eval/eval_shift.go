@@ -0,0 +1,13 @@
+package eval
+
+import "github.com/ape-lang/ape/src/data"
+
+func evalShiftExpression(operator string, leftVal, rightVal int64) data.Data {
+	if rightVal < 0 {
+		return evalError("Negative shift count: %d %s %d", leftVal, operator, rightVal)
+	}
+	if operator == "<<" {
+		return &data.Integer{Value: leftVal << uint64(rightVal)}
+	}
+	return &data.Integer{Value: leftVal >> uint64(rightVal)}
+}
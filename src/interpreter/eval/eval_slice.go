@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+)
+
+// Evaluates a slice expression (`left[start:end]`), the omitted bounds being null
+func evalSliceExpression(node *ast.SliceExpression, env *data.Environment) data.Data {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	bounds := []data.Data{data.NULL, data.NULL}
+	for i, bound := range []ast.Expression{node.Start, node.End} {
+		if bound == nil {
+			continue
+		}
+		bounds[i] = Eval(bound, env)
+		if isError(bounds[i]) {
+			return bounds[i]
+		}
+	}
+
+	slice, err := data.Slice(left, bounds[0], bounds[1])
+	if err != nil {
+		return evalError("%s", err)
+	}
+	return slice
+}
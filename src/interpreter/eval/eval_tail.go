@@ -0,0 +1,78 @@
+package eval
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+)
+
+// tailCall is the result of a function ending with a call, which is made by the caller once the function returned
+// so recursive calls in tail position run in constant stack (see evalCallResult)
+type tailCall struct {
+	function *data.Function
+	args     []data.Data
+}
+
+func (tc *tailCall) Type() data.DataType { return "TAIL_CALL" }
+func (tc *tailCall) Inspect() string     { return "tail call" }
+
+// Evaluates the body of a function, returning the calls in tail position as a tailCall instead of making them
+// Only the statements whose value is the result of the function are in tail position (not the ones in loops or try statements)
+func evalTail(node ast.Node, env *data.Environment) data.Data {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		if len(node.Statements) == 0 {
+			return nil
+		}
+
+		last := len(node.Statements) - 1
+		for _, statement := range node.Statements[:last] {
+			result := Eval(statement, env)
+			if isEscape(result) {
+				return result
+			}
+		}
+		return evalTail(node.Statements[last], env)
+
+	case *ast.ExpressionStatement:
+		return evalTail(node.Expression, env)
+
+	case *ast.ReturnStatement:
+		value := evalTail(node.ReturnValue, env)
+		if isError(value) {
+			return value
+		}
+		return evalReturn(value)
+
+	case *ast.IfExpression:
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if data.IsTruthy(condition) {
+			return evalTail(node.Consequent, env)
+		} else if node.Alternate != nil {
+			return evalTail(node.Alternate, env)
+		}
+		return data.NULL
+
+	case *ast.CallExpression:
+		fn := Eval(node.Function, env)
+		if isError(fn) {
+			return fn
+		}
+
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+
+		// Builtins don't grow the stack, so they are called right away
+		if function, ok := fn.(*data.Function); ok {
+			return &tailCall{function: function, args: args}
+		}
+		return traced(evalCallResult(fn, args, env, node.Position()), node, env)
+	}
+
+	return Eval(node, env)
+}
@@ -1,10 +1,17 @@
 package eval
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ape-lang/ape/src/data"
 	"github.com/ape-lang/ape/src/lexer"
+	"github.com/ape-lang/ape/src/module"
 	"github.com/ape-lang/ape/src/parser"
 )
 
@@ -28,6 +35,16 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"1 + 10 % 4", 3},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"~5", -6},
+		{"~-1", 0},
 	}
 
 	for _, tt := range tests {
@@ -36,6 +53,39 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"3.14", 3.14},
+		{"1e-9", 1e-9},
+		{"-2.5", -2.5},
+		{"1.5 + 2.25", 3.75},
+		{"1 + 0.5", 1.5},
+		{"5 / 2.0", 2.5},
+		{"2.5 * 4", 10.0},
+		{"1.5 > 1", true},
+		{"1 < 1.5", true},
+		{"1 == 1.0", true},
+		{"0.1 + 0.2 == 0.3", false},
+		{"5.5 % 2", 1.5},
+		{"1.5 >= 1.5", true},
+		{"1 <= 0.5", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatData(t, evaluated, expected)
+		case bool:
+			testBooleanData(t, evaluated, expected)
+		}
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -60,6 +110,24 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"3 >= 2", true},
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"1 && 2", true},
+		{"true || false", true},
+		{"false || false", false},
+		{"false || 0", true},
+		{"!true || 1 > 2", false},
+		{"1 < 2 && 2 < 3", true},
+		{"false && 5 / 0", false},
+		{"true || 5 / 0", true},
+		{"false && foobar", false},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -139,6 +207,52 @@ func TestReturnStatements(t *testing.T) {
 	}
 }
 
+func TestComparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] == [1, 2, 3]", false},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`"ape" == "ape"`, true},
+		{`"ape" != "apes"`, true},
+		{"1 == 1.0", true},
+		{`1 == "1"`, false},
+		{`1 != "1"`, true},
+		{"[1] == 1", false},
+		{"let n = if (false) { 1 }; n == n", true},
+		{"(if (false) { 1 }) == false", false},
+		{"let f = fn() {}; f == f", true},
+		{"fn() {} == fn() {}", false},
+		{"let x = 0.0 / 0.0; x == x", false},
+		{"let x = 0.0 / 0.0; x < 1 || x >= 1", false},
+		{`"a" < "b"`, true},
+		{`"ab" < "a"`, false},
+		{`"a" <= "a"`, true},
+		{`"b" > "abc"`, true},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] < [1, 2, 0]", true},
+		{"[2] > [1, 9]", true},
+		{"[1, 2] >= [1, 2]", true},
+		{`[1, "b"] < [1, "a"]`, false},
+		{"[1, 2.5] < [1, 3]", true},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a == b", true},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a < b", false},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a <= b", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanData(t, evaluated, tt.expected)
+	}
+}
+
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -163,6 +277,15 @@ func TestErrorHandling(t *testing.T) {
 		{"foobar", "Identifier not found: foobar"},
 		{`"Hello" - "World"`, "Unknown operator: STRING - STRING"},
 		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{"5 / 0", "division by zero"},
+		{"5 % 0", "division by zero"},
+		{"1 << -1", "Negative shift count: 1 << -1"},
+		{"~true", "Unknown operator: ~BOOLEAN"},
+		{"1.5 & 1", "Unknown operator: FLOAT & INTEGER"},
+		{`1 < "a"`, "Type mismatch: INTEGER < STRING"},
+		{"true > false", "Unknown operator: BOOLEAN > BOOLEAN"},
+		{`[1] < ["a"]`, "Unknown operator: ARRAY < ARRAY"},
+		{"true && foobar", "Identifier not found: foobar"},
 	}
 
 	for _, tt := range tests {
@@ -198,6 +321,300 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"while (false) { 1 }; 5", 5},
+		{"while (true) { break; }; 5", 5},
+		{"let f = fn() { while (true) { return 10; } }; f()", 10},
+		{"let f = fn() { while (false) { 1 } }; f()", nil},
+		{"let last = 0; for (let x in [1, 2, 3]) { let last = x; }; last", 3},
+		{"let last = 0; for (let x in range(10)) { if (x == 3) { break; } let last = x; }; last", 2},
+		{"let last = 0; for (let x in range(5)) { if (x % 2 == 0) { continue; } let last = x; }; last", 3},
+		{"let last = 0; for (let x in range(10, 0, -3)) { let last = x; }; last", 1},
+		{"let f = fn() { for (let x in range(0)) { return 1; } 0 }; f()", 0},
+		{`let last = ""; for (let c in "abñ") { let last = c; }; last`, "ñ"},
+		{`let last = ""; for (let k in {"b": 2, "a": 1}) { let last = k; }; last`, "a"},
+		{`let keys = ""; for (let k in {"c": 1, "a": 2, "b": 3}) { keys = keys + k; }; keys`, "cab"},
+		{"let f = fn(xs) { for (let x in xs) { if (x > 1) { return x; } } }; f([1, 2, 3])", 2},
+		{"let f = fn(xs) { for (let x in xs) { x } }; f([1, 2, 3])", nil},
+		{"for (let x in [1, 2]) { for (let y in [3, 4]) { break; } }; 5", 5},
+		{"for (let x in 5) { x }", "Not iterable: INTEGER"},
+		{"break;", "break outside of a loop"},
+		{"while (true) { fn() { continue; }() }", "continue outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerData(t, evaluated, int64(expected))
+		case nil:
+			testNullData(t, evaluated)
+		case string:
+			var actual string
+			switch evaluated := evaluated.(type) {
+			case *data.String:
+				actual = evaluated.Value
+			case *data.Error:
+				actual = evaluated.Message
+			}
+			if actual != expected {
+				t.Errorf("Expected %q, got %T (%+v)", expected, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestTryStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let r = ""; try { throw("boom") } catch (e) { r = e["message"] }; r`, "boom"},
+		{`let r = ""; try { 1 / 0 } catch (e) { r = e["kind"] }; r`, "RuntimeError"},
+		{`let r = ""; try { throw("boom") } catch (e) { r = e["kind"] }; r`, "Error"},
+		{`let r = 0; try { throw(5) } catch (e) { r = e["value"] }; r`, 5},
+		{`let r = 0; try { r = 1 } finally { r = r * 10 + 2 }; r`, 12},
+		{`let r = 0; try { throw("x") } catch (e) { r = 1 } finally { r = r * 10 + 2 }; r`, 12},
+		{`let r = 0; try { r = 1 + [1, 2, throw("x")][0] } catch (e) { r = 2 }; r + 1`, 3},
+		{`let f = fn() { throw("deep") }; let g = fn() { f() + 1 }; let r = ""; try { g() } catch (e) { r = e["message"] }; r`, "deep"},
+		{`let f = fn(n) { if (n == 0) { throw("bottom") }; f(n - 1) }; let r = ""; try { f(50) } catch (e) { r = e["message"] }; r`, "bottom"},
+		{`let r = 0; let f = fn() { try { return 1 } finally { r = 2 } }; f() + r`, 3},
+		{`let f = fn() { try { throw("a") } finally { return 5 } }; f()`, 5},
+		{`let f = fn() { try { throw("a") } catch (e) { return 6 } }; f()`, 6},
+		{`let r = 0; for (let x in range(5)) { try { if (x == 2) { break } } finally { r += 1 } }; r`, 3},
+		{`let r = 0; for (let x in range(4)) { try { continue } finally { r += x } }; r`, 6},
+		{`let r = ""; try { try { throw("inner") } finally { r = "f" } } catch (e) { r = r + e["message"] }; r`, "finner"},
+		{`let r = ""; try { try { throw("a") } catch (e) { throw(e) } } catch (e) { r = e["message"] }; r`, "a"},
+		{`let f = fn() { throw("x") }; let r = ""; try { f() } catch (e) { r = e["trace"][0] + ", " + e["trace"][1] }; r`, "at f (1:21), at <main> (1:49)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerData(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*data.String)
+			if !ok {
+				t.Errorf("Expected String, got %T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("Expected %q, got %q", expected, str.Value)
+			}
+		}
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let count = fn(n, acc) { if (n == 0) { acc } else { count(n - 1, acc + 1) } }; count(100000, 0)`, 100000},
+		{`let sum = fn(n, acc) { if (n == 0) { return acc; } return sum(n - 1, acc + n); }; sum(100000, 0)`, 5000050000},
+		{`let odd = 0; let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } }; odd = fn(n) { if (n == 0) { false } else { even(n - 1) } }; even(100001)`, false},
+		{`let make = fn(step) { let loop = fn(n, acc) { if (n == 0) { acc } else { loop(n - 1, acc + step) } }; loop }; make(2)(50000, 0)`, 100000},
+		{`let f = fn(a) { len(a) }; f([1, 2, 3])`, 3},
+		{`let g = fn() { throw("x") }; let f = fn() { try { return g(); } catch (e) { return 7; } }; f()`, 7},
+		// The frame of a function is replaced by the function it calls in tail position
+		{`let f = fn() { throw("x") }; let g = fn() { f() }; let r = ""; try { g() } catch (e) { r = e["trace"][0] + ", " + e["trace"][1] }; r`, "at f (1:21), at <main> (1:71)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerData(t, evaluated, int64(expected))
+		case bool:
+			testBooleanData(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*data.String)
+			if !ok {
+				t.Errorf("Expected String, got %T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("Expected %q, got %q", expected, str.Value)
+			}
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+
+	tests := []struct {
+		input    string
+		ctx      context.Context
+		limits   data.Limits
+		expected error // the type of error expected (nil when the program completes)
+	}{
+		{"let x = 1 + 2; x * 3", context.Background(), data.Limits{Steps: 100, Allocations: 10}, nil},
+		{"while (true) {}", context.Background(), data.Limits{Steps: 10000}, &data.StepLimitError{}},
+		{"let f = fn(n) { f(n + 1) }; f(0)", context.Background(), data.Limits{Steps: 10000}, &data.StepLimitError{}},
+		{"try { while (true) {} } catch (e) { 1 } finally { 2 }", context.Background(), data.Limits{Steps: 1000}, &data.StepLimitError{}},
+		{"let a = []; while (true) { a = push(a, 1); }", context.Background(), data.Limits{Allocations: 100000}, &data.AllocationLimitError{}},
+		{"let a = []; let i = 0; while (i < 100000) { a = push(a, i); i += 1; }", context.Background(), data.Limits{Allocations: 1000000}, nil},
+		{`let s = ""; while (true) { s = s + "abc"; }`, context.Background(), data.Limits{Allocations: 100000}, &data.AllocationLimitError{}},
+		{`try { repeat("abc", 500000000) } catch (e) { 1 }`, context.Background(), data.Limits{Allocations: 100000}, &data.AllocationLimitError{}},
+		{`repeat("abc", 1000)`, context.Background(), data.Limits{Allocations: 100000}, nil},
+		{"1 + 2", canceled, data.Limits{}, &data.CanceledError{}},
+		{"while (true) {}", timeout, data.Limits{}, &data.CanceledError{}},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		_, err := EvalContext(tt.ctx, program, data.NewEnvironment(), tt.limits)
+
+		if fmt.Sprintf("%T", err) != fmt.Sprintf("%T", tt.expected) {
+			t.Errorf("wrong error for %q: want=%T, got=%T (%v)", tt.input, tt.expected, err, err)
+		}
+	}
+
+	program := parser.New(lexer.New("1")).ParseProgram()
+	_, err := EvalContext(canceled, program, data.NewEnvironment(), data.Limits{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the error to wrap context.Canceled, got %v", err)
+	}
+}
+
+func TestCallDepth(t *testing.T) {
+	tests := []struct {
+		input    string
+		limits   data.Limits
+		expected string
+	}{
+		{"let f = fn(n) { 1 + f(n + 1) }; f(0)", data.Limits{}, "ERROR: stack overflow"},
+		{"let f = fn(n) { 1 + f(n + 1) }; f(0)", data.Limits{CallDepth: 100}, "ERROR: stack overflow"},
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(99)", data.Limits{CallDepth: 100}, "99"},
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(100)", data.Limits{CallDepth: 100}, "ERROR: stack overflow"},
+		{"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; f(1000)", data.Limits{CallDepth: 100}, "0"},
+		{"let f = fn(n) { 1 + f(n + 1) }; let r = 0; try { f(0) } catch (e) { r = e.message }; r", data.Limits{CallDepth: 100}, "stack overflow"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		result, err := EvalContext(context.Background(), program, data.NewEnvironment(), tt.limits)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", tt.input, err)
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q: want=%q, got=%q", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestImports(t *testing.T) {
+	dir := t.TempDir()
+	for name, source := range map[string]string{
+		"math.ape":         "export let square = fn(x) { x * x }; let hidden = 1; export let twice = fn(x) { x + x };",
+		"counter.ape":      "let count = 0; export let next = fn() { count += 1; count };",
+		"uses_counter.ape": `import "counter"; export let first = counter.next();`,
+		"fib.ape":          "export let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };",
+		"lib/reduce.ape":   "export let reduce = fn(xs, f, acc) { for (let x in xs) { acc = f(acc, x) }; acc };",
+		"lib/sum.ape":      `import { reduce } from "./reduce"; export let sum = fn(xs) { reduce(xs, fn(a, b) { a + b }, 0) };`,
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(module.PathVariable, dir)
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "math"; math.square(3) + math.twice(2)`, 13},
+		{`import { square } from "math"; square(4)`, 16},
+		{`import "lib/sum"; sum.sum([1, 2, 3])`, 6},
+		{`import { fib } from "fib"; fib(10)`, 55},
+		{`import "uses_counter"; import "counter"; counter.next()`, 2},
+		{`import "math"; let hidden = 5; hidden`, 5},
+		{`let f = fn() { 1 }; import "math"; f() + math.square(2)`, 5},
+		{`import "math"; math.hidden`, "module math has no export hidden"},
+		{`import { twice, hidden } from "math"`, "module math has no export hidden"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerData(t, evaluated, int64(expected))
+		case string:
+			err, ok := evaluated.(*data.Error)
+			if !ok {
+				t.Errorf("Expected Error, got %T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if err.Message != expected {
+				t.Errorf("Expected Message to be %q, got %q", expected, err.Message)
+			}
+		}
+	}
+
+	// The program may find its modules with its own loader
+	t.Setenv(module.PathVariable, "")
+	env := data.NewEnvironment()
+	env.SetImports(data.NewImports(module.NewLoader([]string{filepath.Join(dir, "lib")})))
+	program := parser.New(lexer.New(`import "sum"; sum.sum([1, 2])`)).ParseProgram()
+	testIntegerData(t, Eval(program, env), 3)
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let x = 1; x = 2; x", 2},
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x %= 4", 2},
+		{"let a = 1; let b = 2; a = b = 3; a + b", 6},
+		{"let i = 0; let sum = 0; while (i < 5) { i += 1; sum += i; }; sum", 15},
+		{"let xs = [1, 2, 3]; xs[2] += 10; xs[2]", 13},
+		{"let xs = [1, 2]; let ys = xs; ys[0] = 9; xs[0]", 9},
+		{`let h = {"a": 1}; h["a"] += 1; h["b"] = 5; h["a"] + h["b"]`, 7},
+		{"let counter = fn() { let count = 0; fn() { count += 1; count } }; let c = counter(); c(); c(); c()", 3},
+		{"let make = fn() { let n = 0; fn() { n += 1 } }; let a = make(); let b = make(); a(); a(); b()", 1},
+		{"x = 1", "Identifier not found: x"},
+		{"let xs = [1]; xs[1] = 2", "array index out of range: 1"},
+		{`let s = "a"; s[0] = "b"`, "index assignment not supported: STRING"},
+		{`let x = "a"; x -= 1`, "Type mismatch: STRING - INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerData(t, evaluated, int64(expected))
+		case string:
+			err, ok := evaluated.(*data.Error)
+			if !ok {
+				t.Errorf("Expected Error, got %T(%+v)", evaluated, evaluated)
+				continue
+			}
+			if err.Message != expected {
+				t.Errorf("Expected Message to be %q, got %q", expected, err.Message)
+			}
+		}
+	}
+}
+
 func TestFunctionData(t *testing.T) {
 	input := "fn(x) { x + 2; };"
 	evaluated := testEval(input)
@@ -260,6 +677,8 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("héllo wörld")`, 11},
+		{`byte_len("héllo wörld")`, 13},
 		{`len(1)`, "argument to 'len' not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
 	}
@@ -284,6 +703,133 @@ func TestBuiltinFunctions(t *testing.T) {
 		}
 	}
 }
+func TestHigherOrderBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // the inspected result
+	}{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, "[2, 4, 6]"},
+		{`map(range(3), fn(x) { x + 1 })`, "[1, 2, 3]"},
+		{`map(["a", "bc"], len)`, "[1, 2]"},
+		{`filter([1, 2, 3, 4], fn(x) { x % 2 == 0 })`, "[2, 4]"},
+		{`reduce([1, 2, 3], fn(acc, x) { acc + x }, 10)`, "16"},
+		{`reduce([1, 2, 3], fn(acc, x) { acc * x })`, "6"},
+		{`reduce([], fn(acc, x) { acc + x })`, "null"},
+		{`let sum = 0; each([1, 2, 3], fn(x) { sum = sum + x }); sum`, "6"},
+		{`sort_by([3, 1, 2], fn(x) { x })`, "[1, 2, 3]"},
+		{`sort_by([3, 1.5, 2], fn(x) { -x })`, "[3, 2, 1.5]"},
+		{`map(sort_by([[2, 1], [1, 2], [1, 1]], fn(p) { p[0] }), fn(p) { p[1] })`, "[2, 1, 1]"},
+		{`sort_by(["bb", "a", "ccc"], fn(s) { s })`, "[a, bb, ccc]"},
+		{`find([1, 2, 3], fn(x) { x > 1 })`, "2"},
+		{`find([1], fn(x) { false })`, "null"},
+		{`any([1, 2], fn(x) { x > 1 })`, "true"},
+		{`all([1, 2], fn(x) { x > 1 })`, "false"},
+		{`all([], fn(x) { false })`, "true"},
+		{`let calls = 0; any([1, 2, 3], fn(x) { calls = calls + 1; x == 2 }); calls`, "2"},
+		{`map([1, 2], fn(x) { reduce(map([x, x], fn(y) { y * 10 }), fn(a, b) { a + b }) })`, "[20, 40]"},
+		{`let r = ""; try { map([1], fn(x) { throw("bad") }) } catch (e) { r = e.message }; r`, "bad"},
+		{`let r = ""; try { map([1], fn(a, b) { a }) } catch (e) { r = e.message }; r`, "wrong number of arguments: want=2, got=1"},
+		{`map(1, fn(x) { x })`, `ERROR: first argument to 'map' must be iterable, got INTEGER`},
+		{`filter([1], 2)`, `ERROR: second argument to 'filter' must be a function, got INTEGER`},
+		{`sort_by([1, "a"], fn(x) { x })`, `ERROR: keys of 'sort_by' must be all numbers or all strings, got INTEGER and STRING`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: want=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // the inspected result
+	}{
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[5]`, "null"},
+		{`"héllo"[-1]`, "null"},
+		{`"héllo wörld"[1:4]`, "éll"},
+		{`"héllo"[:2]`, "hé"},
+		{`"héllo"[3:]`, "lo"},
+		{`"héllo"[:]`, "héllo"},
+		{`"abc"[-5:10]`, "abc"},
+		{`"abc"[2:1]`, ""},
+		{`[1, 2, 3, 4][1:3]`, "[2, 3]"},
+		{`let a = [1, 2, 3]; let b = a[1:]; b[0] = 5; a`, "[1, 2, 3]"},
+		{`let r = ""; try { "abc"["a":] } catch (e) { r = e.message }; r`, "slice bounds must be INTEGER, got STRING"},
+		{`let r = ""; try { 1[0:1] } catch (e) { r = e.message }; r`, "slice operator not supported: INTEGER"},
+		{`split("a,b,,c", ",") == ["a", "b", "", "c"]`, "true"},
+		{`split("hé", "") == ["h", "é"]`, "true"},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`join([], "-")`, ""},
+		{`trim("  ape \n")`, "ape"},
+		{`upper("héllo")`, "HÉLLO"},
+		{`lower("HÉLLO")`, "héllo"},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`contains("héllo", "él")`, "true"},
+		{`contains("héllo", "x")`, "false"},
+		{`starts_with("héllo", "hé")`, "true"},
+		{`ends_with("héllo", "lo")`, "true"},
+		{`index_of("héllo", "l")`, "2"},
+		{`index_of("héllo", "x")`, "-1"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`chars("hé!") == ["h", "é", "!"]`, "true"},
+		{`let s = "héllo"; let r = ""; for (let i in range(len(s))) { r = s[i] + r }; r`, "olléh"},
+		{`join(["a", 1], "")`, "ERROR: elements joined by 'join' must be STRING, got INTEGER"},
+		{`upper(1)`, "ERROR: argument to 'upper' must be STRING, got INTEGER"},
+		{`replace("a", "b")`, "ERROR: wrong number of arguments. got=2, want=3"},
+		{`repeat("a", -1)`, "ERROR: 'repeat' count must not be negative, got -1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: want=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // the inspected result
+	}{
+		{`len({"a": 1, "b": 2})`, "2"},
+		{`len({})`, "0"},
+		{`keys({"b": 1, "a": 2})`, "[b, a]"},
+		{`values({"b": 1, "a": 2})`, "[1, 2]"},
+		{`entries({"b": 1, 2: [3]})`, "[[b, 1], [2, [3]]]"},
+		{`has({"a": 1}, "a")`, "true"},
+		{`has({"a": 1}, "b")`, "false"},
+		{`delete({"a": 1, "b": 2, "c": 3}, "b")`, "{a: 1, c: 3}"},
+		{`delete({"a": 1}, "b")`, "{a: 1}"},
+		{`let h = {"a": 1}; delete(h, "a"); h`, "{a: 1}"},
+		{`merge({"a": 1, "b": 2}, {"c": 3, "a": 4})`, "{a: 4, b: 2, c: 3}"},
+		{`let h = {"a": 1}; merge(h, {"b": 2}); h`, "{a: 1}"},
+		{`let h = {}; for (let e in entries({"x": 1, "y": 2})) { h = merge(h, {e[1]: e[0]}) }; h`, "{1: x, 2: y}"},
+		{`keys(1)`, "ERROR: argument to 'keys' must be HASH, got INTEGER"},
+		{`has({}, [])`, "ERROR: unusable as hash key: ARRAY"},
+		{`delete({})`, "ERROR: wrong number of arguments. got=1, want=2"},
+		{`merge({}, [])`, "ERROR: arguments to 'merge' must be HASH, got ARRAY"},
+		{`set({"a": 1}, "b", 2)`, "{a: 1, b: 2}"},
+		{`let h = {"a": 1}; set(h, "a", 2); h`, "{a: 1}"},
+		{`set([1, 2, 3], 1, 5)`, "[1, 5, 3]"},
+		{`let a = [1, 2]; let b = set(push(a, 3), 0, 4); a[0] = 5; [a, b]`, "[[5, 2], [4, 2, 3]]"},
+		{`set([1], 1, 2)`, "ERROR: array index out of range: 1"},
+		{`set("a", 0, 1)`, "ERROR: argument to 'set' must be ARRAY or HASH, got STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: want=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 	evaluated := testEval(input)
@@ -298,6 +844,21 @@ func TestStringLiteral(t *testing.T) {
 	}
 }
 
+func TestStringEscapes(t *testing.T) {
+	input := `"tab:\t, quote:\", unicode:\u{1F600}" + "\n"`
+	evaluated := testEval(input)
+	str, ok := evaluated.(*data.String)
+
+	if !ok {
+		t.Fatalf("Expected Data to be String, got %T (%+v)", evaluated, evaluated)
+	}
+
+	expected := "tab:\t, quote:\", unicode:😀\n"
+	if str.Value != expected {
+		t.Errorf("Expected String to equal %q, got %q", expected, str.Value)
+	}
+}
+
 func TestStringConcatenation(t *testing.T) {
 	input := `"Hello" + " " + "World!"`
 	evaluated := testEval(input)
@@ -321,14 +882,14 @@ func TestArrayLiterals(t *testing.T) {
 		t.Fatalf("Expected Data to be Array, got %T (%+v)", evaluated, evaluated)
 	}
 
-	if len(result.Elements) != 3 {
+	if result.Len() != 3 {
 		t.Fatalf("Expected Array to have 3 elements, got %d",
-			len(result.Elements))
+			result.Len())
 	}
 
-	testIntegerData(t, result.Elements[0], 1)
-	testIntegerData(t, result.Elements[1], 4)
-	testIntegerData(t, result.Elements[2], 6)
+	testIntegerData(t, result.Get(0), 1)
+	testIntegerData(t, result.Get(1), 4)
+	testIntegerData(t, result.Get(2), 6)
 }
 
 func TestArrayIndexExpressions(t *testing.T) {
@@ -386,12 +947,12 @@ func TestHashLiterals(t *testing.T) {
 		data.HashData(data.FALSE):                   6,
 	}
 
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
 	}
 
 	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+		pair, ok := result.Get(expectedKey)
 		if !ok {
 			t.Errorf("no pair for given key in Pairs")
 		}
@@ -411,6 +972,9 @@ func TestHashIndexExpressions(t *testing.T) {
 		{`{5: 5}[5]`, 5},
 		{`{true: 5}[true]`, 5},
 		{`{false: 5}[false]`, 5},
+		{`{1: 5}[1.0]`, 5},
+		{`{2.0: 5}[2]`, 5},
+		{`{1: 5}[1.5]`, nil},
 	}
 
 	for _, tt := range tests {
@@ -450,6 +1014,22 @@ func testIntegerData(t *testing.T, d data.Data, expected int64) bool {
 	return true
 }
 
+func testFloatData(t *testing.T, d data.Data, expected float64) bool {
+	result, ok := d.(*data.Float)
+
+	if !ok {
+		t.Errorf("Expected Data to be Float, got %T (%+v)", d, d)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("Expected Data to equal %g, got %g", expected, result.Value)
+		return false
+	}
+
+	return true
+}
+
 func testBooleanData(t *testing.T, d data.Data, expected bool) bool {
 	result, ok := d.(*data.Boolean)
 	if !ok {
@@ -0,0 +1,57 @@
+package eval
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/data"
+)
+
+func evalTryStatement(ts *ast.TryStatement, env *data.Environment) data.Data {
+	result := Eval(ts.Body, env)
+	if isFatal(result) {
+		return result
+	}
+
+	if err, ok := result.(*data.Error); ok && ts.Catch != nil {
+		env.Set(ts.Name.Value, err.Exception)
+		result = Eval(ts.Catch, env)
+		if isFatal(result) {
+			return result
+		}
+	}
+
+	// The finally block always runs, an error or jump out of it replaces the result of the other blocks
+	if ts.Finally != nil {
+		if final := Eval(ts.Finally, env); isEscape(final) {
+			return final
+		}
+	}
+
+	if isEscape(result) {
+		return result
+	}
+	return data.NULL
+}
+
+// Checks whether a result is an error stopping the program for good (skipping the catch and finally blocks)
+func isFatal(d data.Data) bool {
+	err, ok := d.(*data.Error)
+	return ok && err.Fatal != nil
+}
+
+// Checks whether a result leaves the enclosing block (an error, a return, a break or a continue)
+func isEscape(d data.Data) bool {
+	switch d.(type) {
+	case *data.Error, *data.Return, *data.Break, *data.Continue:
+		return true
+	default:
+		return false
+	}
+}
+
+func evalExceptionIndexExpression(exception, index data.Data) data.Data {
+	field, ok := exception.(*data.Exception).Field(index.(*data.String).Value)
+	if !ok {
+		return data.NULL
+	}
+	return field
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/ape-lang/ape/src/data"
 	"github.com/ape-lang/ape/src/interpreter/eval"
@@ -29,7 +30,7 @@ func Start(in io.Reader, out io.Writer) {
 		program := p.ParseProgram()
 
 		if len(p.Errors()) != 0 {
-			printErrors(out, p.Errors())
+			printErrors(out, line, p.Errors())
 			continue
 		}
 
@@ -42,12 +43,14 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
-func printErrors(out io.Writer, errors []string) {
+func printErrors(out io.Writer, source string, errors []*parser.ParseError) {
 	io.WriteString(out, "Input could not be parsed!\n")
 	io.WriteString(out, " Errors:\n")
 
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+	for _, err := range errors {
+		for _, line := range strings.Split(err.Render(source), "\n") {
+			io.WriteString(out, "\t"+line+"\n")
+		}
 	}
 }
 
@@ -0,0 +1,76 @@
+package lexer
+
+import (
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+// Skips whitespace and comments, returning the doc (the line comments right before the next token)
+// Returns false and the position of the comment if a block comment is not terminated
+func (l *Lexer) skipTrivia() (string, token.Position, bool) {
+	doc := []string{}
+	newlines := 0                  // Newlines since the last line comment
+	trailing := l.charPosition > 0 // Whether comments are on the same line as the previous token
+
+	for {
+		switch {
+		case l.char == '\n':
+			trailing = false
+			newlines++
+			// A blank line detaches the comments from the next token
+			if newlines > 1 {
+				doc = doc[:0]
+			}
+			l.advanceChar()
+		case l.char == ' ' || l.char == '\t' || l.char == '\r':
+			l.advanceChar()
+		case l.char == '/' && l.peekChar() == '/':
+			comment := l.readLineComment()
+			if !trailing {
+				doc = append(doc, comment)
+				newlines = 0
+			}
+		case l.char == '/' && l.peekChar() == '*':
+			start := l.currentPosition()
+			if !l.skipBlockComment() {
+				return "", start, false
+			}
+			doc = doc[:0]
+		default:
+			return strings.Join(doc, "\n"), l.currentPosition(), true
+		}
+	}
+}
+
+// Reads a line comment and returns its text (without the slashes and the first space)
+func (l *Lexer) readLineComment() string {
+	l.advanceChar()
+	l.advanceChar()
+
+	text := l.readWhile(func(ch rune) bool { return ch != '\n' && ch != 0 })
+	return strings.TrimPrefix(text, " ")
+}
+
+// Skips a (possibly nested) block comment, returns false if the input ends before it is closed
+func (l *Lexer) skipBlockComment() bool {
+	depth := 0
+
+	for l.char != 0 {
+		switch {
+		case l.char == '/' && l.peekChar() == '*':
+			depth++
+			l.advanceChar()
+		case l.char == '*' && l.peekChar() == '/':
+			depth--
+			l.advanceChar()
+		}
+		l.advanceChar()
+
+		if depth == 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,19 +1,31 @@
 package lexer
 
 import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/ape-lang/ape/src/token"
 )
 
 type Lexer struct {
 	input        string // The input string
-	char         byte   // The current character
+	file         string // The name of the file the input was read from
+	char         rune   // The current character
 	charPosition int    // The position of the current character
 	position     int    // The position of the cursor
+	line         int    // The line of the current character
+	column       int    // The column of the current character
 }
 
 // New Lexer creates and returns a new Lexer
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewWithFile(input, "")
+}
+
+// NewWithFile creates and returns a new Lexer whose token positions reference the given file
+func NewWithFile(input string, file string) *Lexer {
+	l := &Lexer{input: input, file: file, line: 1}
 	l.advanceChar()
 	return l
 }
@@ -22,37 +34,82 @@ func New(input string) *Lexer {
 func (l *Lexer) NextToken() token.Token {
 	var t token.Token
 
-	l.skipWhitespace()
+	doc, start, ok := l.skipTrivia()
+	if ok {
+		t = l.scanToken()
+		t.Doc = doc
+	} else {
+		t = token.Token{Type: token.ILLEGAL, Literal: "Unterminated block comment"}
+	}
+
+	t.Position = start
+	t.End = l.currentPosition()
+
+	return t
+}
+
+// Scans the token starting at the current character
+func (l *Lexer) scanToken() token.Token {
+	var t token.Token
 
 	switch l.char {
 	case '+':
-		t = token.New(token.PLUS, l.char)
+		t = l.newOperatorToken(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		t = token.New(token.MINUS, l.char)
+		t = l.newOperatorToken(token.MINUS, token.MINUS_ASSIGN)
 	case '*':
-		t = token.New(token.ASTERISK, l.char)
+		t = l.newOperatorToken(token.ASTERISK, token.ASTERISK_ASSIGN)
 	case '/':
-		t = token.New(token.SLASH, l.char)
+		t = l.newOperatorToken(token.SLASH, token.SLASH_ASSIGN)
+	case '%':
+		t = l.newOperatorToken(token.PERCENT, token.PERCENT_ASSIGN)
 
 	case '<':
-		t = token.New(token.LT, l.char)
+		switch l.peekChar() {
+		case '=':
+			t = l.newDoubleToken(token.LTE)
+		case '<':
+			t = l.newDoubleToken(token.LSHIFT)
+		default:
+			t = token.New(token.LT, l.char)
+		}
 	case '>':
-		t = token.New(token.GT, l.char)
+		switch l.peekChar() {
+		case '=':
+			t = l.newDoubleToken(token.GTE)
+		case '>':
+			t = l.newDoubleToken(token.RSHIFT)
+		default:
+			t = token.New(token.GT, l.char)
+		}
+
+	case '&':
+		if l.peekChar() == '&' {
+			t = l.newDoubleToken(token.AND)
+		} else {
+			t = token.New(token.AMPERSAND, l.char)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			t = l.newDoubleToken(token.OR)
+		} else {
+			t = token.New(token.PIPE, l.char)
+		}
+	case '^':
+		t = token.New(token.CARET, l.char)
+	case '~':
+		t = token.New(token.TILDE, l.char)
 
 	case '=':
 		if l.peekChar() == '=' {
-			char := l.char
-			l.advanceChar()
-			t = token.Token{Type: token.EQ, Literal: string(char) + string(l.char)}
+			t = l.newDoubleToken(token.EQ)
 		} else {
 			t = token.New(token.ASSIGN, l.char)
 		}
 
 	case '!':
 		if l.peekChar() == '=' {
-			char := l.char
-			l.advanceChar()
-			t = token.Token{Type: token.NEQ, Literal: string(char) + string(l.char)}
+			t = l.newDoubleToken(token.NEQ)
 		} else {
 			t = token.New(token.BANG, l.char)
 		}
@@ -69,6 +126,8 @@ func (l *Lexer) NextToken() token.Token {
 		t = token.New(token.BRACKETL, l.char)
 	case ']':
 		t = token.New(token.BRACKETR, l.char)
+	case '.':
+		t = token.New(token.DOT, l.char)
 	case ',':
 		t = token.New(token.COMMA, l.char)
 	case ':':
@@ -77,8 +136,12 @@ func (l *Lexer) NextToken() token.Token {
 		t = token.New(token.SEMICOLON, l.char)
 
 	case '"':
-		t.Type = token.STRING
-		t.Literal = l.readString()
+		value, err := l.readString()
+		if err != "" {
+			t = token.Token{Type: token.ILLEGAL, Literal: err}
+		} else {
+			t = token.Token{Type: token.STRING, Literal: value}
+		}
 
 	case 0:
 		t.Literal = ""
@@ -90,11 +153,10 @@ func (l *Lexer) NextToken() token.Token {
 			t.Type = token.LookupIdent(t.Literal)
 			return t
 		} else if isDigit(l.char) {
-			t.Type = token.INT
-			t.Literal = l.readNumber()
+			t.Literal, t.Type = l.readNumber()
 			return t
 		} else {
-			t = token.New(token.ILLEGAL, l.char)
+			t = token.Token{Type: token.ILLEGAL, Literal: "Unexpected character '" + string(l.char) + "'"}
 		}
 	}
 
@@ -102,21 +164,57 @@ func (l *Lexer) NextToken() token.Token {
 	return t
 }
 
+// Creates a token out of the current and the next character (advancing to the latter)
+func (l *Lexer) newDoubleToken(tokenType token.TokenType) token.Token {
+	char := l.char
+	l.advanceChar()
+	return token.Token{Type: tokenType, Literal: string(char) + string(l.char)}
+}
+
+// Creates an operator token, or its compound assignment token when followed by '='
+func (l *Lexer) newOperatorToken(tokenType, assignType token.TokenType) token.Token {
+	if l.peekChar() == '=' {
+		return l.newDoubleToken(assignType)
+	}
+	return token.New(tokenType, l.char)
+}
+
 // Sets the character and advances the positions
 func (l *Lexer) advanceChar() {
+	if l.char == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	l.char = l.peekChar()
 	l.charPosition = l.position
-	l.position++
+	l.position += l.charWidth()
+}
+
+// Returns the byte width of the current character (1 at the end of the input)
+func (l *Lexer) charWidth() int {
+	if l.charPosition >= len(l.input) {
+		return 1
+	}
+	_, width := utf8.DecodeRuneInString(l.input[l.charPosition:])
+	return width
 }
 
-func (l *Lexer) peekChar() byte {
+// Returns the source position of the current character
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{File: l.file, Line: l.line, Column: l.column}
+}
+
+func (l *Lexer) peekChar() rune {
 	if l.position < len(l.input) {
-		return l.input[l.position]
+		char, _ := utf8.DecodeRuneInString(l.input[l.position:])
+		return char
 	}
 	return 0
 }
 
-func (l *Lexer) readWhile(predicate func(byte) bool) string {
+func (l *Lexer) readWhile(predicate func(rune) bool) string {
 	pos := l.charPosition
 	for predicate(l.char) {
 		l.advanceChar()
@@ -128,17 +226,107 @@ func (l *Lexer) readIdentifier() string {
 	return l.readWhile(isLetter)
 }
 
-func (l *Lexer) readNumber() string {
-	return l.readWhile(isDigit)
+// Reads an integer or a float (with an optional fraction and exponent) and returns it with its type
+func (l *Lexer) readNumber() (string, token.TokenType) {
+	pos := l.charPosition
+	tokenType := token.TokenType(token.INT)
+
+	l.readWhile(isDigit)
+
+	if l.char == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.advanceChar()
+		l.readWhile(isDigit)
+	}
+
+	if l.char == 'e' || l.char == 'E' {
+		// Only consume the exponent if it is well-formed, otherwise leave it to the next token
+		next := l.input[l.position:]
+		if len(next) > 0 && (next[0] == '+' || next[0] == '-') {
+			next = next[1:]
+		}
+		if len(next) > 0 && isDigit(rune(next[0])) {
+			tokenType = token.FLOAT
+			l.advanceChar()
+			if l.char == '+' || l.char == '-' {
+				l.advanceChar()
+			}
+			l.readWhile(isDigit)
+		}
+	}
+
+	return l.input[pos:l.charPosition], tokenType
+}
+
+// Reads a string literal resolving its escape sequences, returns an error message if it is malformed
+func (l *Lexer) readString() (string, string) {
+	var sb strings.Builder
+
+	for {
+		l.advanceChar() // Skips the first double quotes (and the previous character afterwards)
+
+		switch l.char {
+		case '"':
+			return sb.String(), ""
+		case 0:
+			return "", "Unterminated string"
+		case '\\':
+			l.advanceChar()
+			if l.char == 0 {
+				return "", "Unterminated string"
+			}
+			char, err := l.readEscape()
+			if err != "" {
+				l.skipString()
+				return "", err
+			}
+			sb.WriteRune(char)
+		default:
+			sb.WriteRune(l.char)
+		}
+	}
 }
 
-func (l *Lexer) readString() string {
-	l.advanceChar() // Skips the first double quotes
-	return l.readWhile(whileString)
+// Reads the escape sequence starting at the current character (right after the backslash)
+func (l *Lexer) readEscape() (rune, string) {
+	switch l.char {
+	case 'n':
+		return '\n', ""
+	case 't':
+		return '\t', ""
+	case 'r':
+		return '\r', ""
+	case '"':
+		return '"', ""
+	case '\\':
+		return '\\', ""
+	case 'u':
+		if l.peekChar() != '{' {
+			return 0, "Invalid unicode escape, expected '\\u{...}'"
+		}
+		l.advanceChar()
+		l.advanceChar()
+
+		digits := l.readWhile(isHexDigit)
+		if l.char == 0 {
+			return 0, "Unterminated string"
+		}
+		value, err := strconv.ParseUint(digits, 16, 32)
+		if l.char != '}' || err != nil || len(digits) > 6 || !utf8.ValidRune(rune(value)) {
+			return 0, "Invalid unicode escape '\\u{" + digits + "}'"
+		}
+		return rune(value), ""
+	default:
+		return 0, "Invalid escape sequence '\\" + string(l.char) + "'"
+	}
 }
 
-func (l *Lexer) skipWhitespace() {
-	for l.char == ' ' || l.char == '\t' || l.char == '\n' || l.char == '\r' {
+// Skips the rest of a malformed string, so its content is not lexed as code
+func (l *Lexer) skipString() {
+	for l.char != '"' && l.char != 0 {
+		if l.char == '\\' {
+			l.advanceChar()
+		}
 		l.advanceChar()
 	}
 }
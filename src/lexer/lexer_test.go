@@ -17,7 +17,7 @@ func TestNextToken(t *testing.T) {
 
 		let result = add(five, ten);
 		
-		!-/*5;
+		!-/ *5;
 		5 < 10 > 5;
 
 		if (5 < 10) {
@@ -155,3 +155,367 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  x == 10;"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+		expectedEnd    int
+	}{
+		{token.LET, 1, 1, 4},
+		{token.IDENT, 1, 5, 6},
+		{token.ASSIGN, 1, 7, 8},
+		{token.INT, 1, 9, 10},
+		{token.SEMICOLON, 1, 10, 11},
+		{token.IDENT, 2, 3, 4},
+		{token.EQ, 2, 5, 7},
+		{token.INT, 2, 8, 10},
+		{token.SEMICOLON, 2, 10, 11},
+		{token.EOF, 2, 11, 12},
+	}
+
+	l := NewWithFile(input, "test.ape")
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Position.File != "test.ape" {
+			t.Fatalf("tests[%d] - Bad File. Expected 'test.ape', got '%s' instead", i, tok.Position.File)
+		}
+
+		if tok.Position.Line != tt.expectedLine || tok.Position.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - Bad Position. Expected '%d:%d', got '%d:%d' instead",
+				i, tt.expectedLine, tt.expectedColumn, tok.Position.Line, tok.Position.Column)
+		}
+
+		if tok.End.Column != tt.expectedEnd {
+			t.Fatalf("tests[%d] - Bad End Column. Expected '%d', got '%d' instead",
+				i, tt.expectedEnd, tok.End.Column)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `
+		// a line comment
+		let x = 5; // a trailing comment
+		/* a block
+		   comment */
+		x / 2;
+		/* a /* nested */ block comment */
+		x//
+	`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Bad Literal. Expected '%q', got '%q' instead",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := New("let x = 5;\n/* never /* closed */")
+
+	for i := 0; i < 5; i++ {
+		l.NextToken()
+	}
+
+	tok := l.NextToken()
+	if tok.Type != token.ILLEGAL {
+		t.Fatalf("Bad Token Type. Expected '%q', got '%q' instead", token.ILLEGAL, tok.Type)
+	}
+
+	if tok.Literal != "Unterminated block comment" {
+		t.Fatalf("Bad Literal. Expected 'Unterminated block comment', got '%q' instead", tok.Literal)
+	}
+
+	if tok.Position.Line != 2 || tok.Position.Column != 1 {
+		t.Fatalf("Bad Position. Expected '2:1', got '%s' instead", tok.Position)
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("Bad Token Type. Expected '%q', got '%q' instead", token.EOF, tok.Type)
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `
+		// detached comment
+
+		// adds two numbers
+		// and returns the result
+		let add = 1;
+		let sub = 2; // trailing
+		let mul = 3;
+	`
+
+	l := New(input)
+
+	expected := map[string]string{
+		"add": "adds two numbers\nand returns the result",
+		"sub": "",
+		"mul": "",
+	}
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type != token.LET {
+			continue
+		}
+
+		name := l.NextToken()
+		if tok.Doc != expected[name.Literal] {
+			t.Errorf("Bad Doc for %s. Expected %q, got %q instead", name.Literal, expected[name.Literal], tok.Doc)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"foo bar"`, token.STRING, "foo bar"},
+		{`"line\nnext\ttab"`, token.STRING, "line\nnext\ttab"},
+		{`"say \"hi\" \\ bye"`, token.STRING, `say "hi" \ bye`},
+		{`"\u{48}\u{e9}\u{1F600}"`, token.STRING, "Hé😀"},
+		{`"héllo wörld"`, token.STRING, "héllo wörld"},
+		{`"unterminated`, token.ILLEGAL, "Unterminated string"},
+		{`"bad \q escape"`, token.ILLEGAL, `Invalid escape sequence '\q'`},
+		{`"\u{110000}"`, token.ILLEGAL, `Invalid unicode escape '\u{110000}'`},
+		{`"\u{zz}"`, token.ILLEGAL, `Invalid unicode escape '\u{}'`},
+		{`"trailing \`, token.ILLEGAL, "Unterminated string"},
+		{`"\u{48`, token.ILLEGAL, "Unterminated string"},
+		{`"\u48"`, token.ILLEGAL, `Invalid unicode escape, expected '\u{...}'`},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Bad Literal. Expected '%q', got '%q' instead",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead", i, token.EOF, tok.Type)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `let café = "ü"; naïve + 日本;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1},
+		{token.IDENT, "café", 5},
+		{token.ASSIGN, "=", 10},
+		{token.STRING, "ü", 12},
+		{token.SEMICOLON, ";", 15},
+		{token.IDENT, "naïve", 17},
+		{token.PLUS, "+", 23},
+		{token.IDENT, "日本", 25},
+		{token.SEMICOLON, ";", 27},
+		{token.EOF, "", 28},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Bad Literal. Expected '%q', got '%q' instead",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Position.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - Bad Column. Expected '%d', got '%d' instead",
+				i, tt.expectedColumn, tok.Position.Column)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	input := `a <= b >= c % d && e || f & g | h ^ i << j >> ~k < l > m += -= *= /= %= =`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LTE, "<="},
+		{token.IDENT, "b"},
+		{token.GTE, ">="},
+		{token.IDENT, "c"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "d"},
+		{token.AND, "&&"},
+		{token.IDENT, "e"},
+		{token.OR, "||"},
+		{token.IDENT, "f"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "g"},
+		{token.PIPE, "|"},
+		{token.IDENT, "h"},
+		{token.CARET, "^"},
+		{token.IDENT, "i"},
+		{token.LSHIFT, "<<"},
+		{token.IDENT, "j"},
+		{token.RSHIFT, ">>"},
+		{token.TILDE, "~"},
+		{token.IDENT, "k"},
+		{token.LT, "<"},
+		{token.IDENT, "l"},
+		{token.GT, ">"},
+		{token.IDENT, "m"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.MINUS_ASSIGN, "-="},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.SLASH_ASSIGN, "/="},
+		{token.PERCENT_ASSIGN, "%="},
+		{token.ASSIGN, "="},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Bad Literal. Expected '%q', got '%q' instead",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestLoopKeywords(t *testing.T) {
+	input := `while for in break continue inner try catch finally`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.WHILE, "while"},
+		{token.FOR, "for"},
+		{token.IN, "in"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
+		{token.IDENT, "inner"},
+		{token.TRY, "try"},
+		{token.CATCH, "catch"},
+		{token.FINALLY, "finally"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Bad Literal. Expected '%q', got '%q' instead",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `5 3.14 0.5 1e9 1e-9 2.5E+3 7.method 1e x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "5"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "0.5"},
+		{token.FLOAT, "1e9"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "2.5E+3"},
+		{token.INT, "7"},
+		{token.DOT, "."},
+		{token.IDENT, "method"},
+		{token.INT, "1"},
+		{token.IDENT, "e"},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Bad Token Type. Expected '%q', got '%q' instead",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Bad Literal. Expected '%q', got '%q' instead",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
@@ -4,18 +4,84 @@ import (
 	"fmt"
 	"os"
 	"os/user"
+	"strings"
 
+	"github.com/ape-lang/ape/src/compiler/compiler"
 	"github.com/ape-lang/ape/src/compiler/repl"
 )
 
+const usage = `Usage:
+	ape                               start the interactive REPL
+	ape run [-O0] <file> [args...]    run a script file (or a compiled .apec file)
+	ape build [-O0] <file> [output]   compile a script file into a .apec file
+	ape disasm [-O0] <file>           print the bytecode of a script file (or a compiled .apec file)
+
+Scripts are compiled with optimizations (-O1) unless -O0 is given.
+`
+
 func main() {
-	user, err := user.Current()
+	if len(os.Args) > 1 {
+		os.Exit(command(os.Args[1], os.Args[2:]))
+	}
 
+	user, err := user.Current()
 	if err != nil {
 		panic(err)
 	}
 
 	fmt.Printf("Ape 0.0.1 (%s)\n", user.Username)
-
 	repl.Start(os.Stdin, os.Stdout)
 }
+
+// Executes the given command and returns the exit code of the process
+func command(name string, args []string) int {
+	optimization, args, ok := optimizationFlag(args)
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	switch name {
+	case "run":
+		if len(args) < 1 {
+			fmt.Fprint(os.Stderr, usage)
+			return 2
+		}
+		return run(args[0], args[1:], optimization)
+	case "build":
+		if len(args) < 1 || len(args) > 2 {
+			fmt.Fprint(os.Stderr, usage)
+			return 2
+		}
+		output := ""
+		if len(args) == 2 {
+			output = args[1]
+		}
+		return build(args[0], output, optimization)
+	case "disasm":
+		if len(args) != 1 {
+			fmt.Fprint(os.Stderr, usage)
+			return 2
+		}
+		return disasm(args[0], optimization)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command '%s'\n\n%s", name, usage)
+		return 2
+	}
+}
+
+// Reads the optimization level flag given before the file (-O0 or -O1), returning the remaining args
+func optimizationFlag(args []string) (int, []string, bool) {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "-O") {
+		return compiler.O1, args, true
+	}
+
+	switch args[0] {
+	case "-O0":
+		return compiler.O0, args[1:], true
+	case "-O1":
+		return compiler.O1, args[1:], true
+	default:
+		return 0, nil, false
+	}
+}
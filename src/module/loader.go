@@ -0,0 +1,131 @@
+// Finding and parsing the source files of modules
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/lexer"
+	"github.com/ape-lang/ape/src/parser"
+)
+
+// PathVariable is the environment variable listing the directories searched for modules
+const PathVariable = "APE_PATH"
+
+// Extension is the file extension of modules, which can be left out of import paths
+const Extension = ".ape"
+
+// Loader finds and parses the source files of modules, keeping track of the modules being loaded
+type Loader struct {
+	Path    []string // The directories searched for modules, in order
+	loading []string // The files of the modules being loaded, innermost last
+}
+
+// NewLoader creates a loader searching the given directories
+func NewLoader(path []string) *Loader {
+	return &Loader{Path: path}
+}
+
+// DefaultPath returns the directories listed in APE_PATH, followed by the working directory
+func DefaultPath() []string {
+	path := []string{}
+	for _, dir := range filepath.SplitList(os.Getenv(PathVariable)) {
+		if dir != "" {
+			path = append(path, dir)
+		}
+	}
+	return append(path, ".")
+}
+
+// Resolve returns the absolute file path of an imported module
+// Paths starting with "./" or "../" are relative to the importing file, others are searched in the loader path
+func (l *Loader) Resolve(name string, from string) (string, error) {
+	if filepath.Ext(name) != Extension {
+		name += Extension
+	}
+
+	dirs := l.Path
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		dirs = []string{filepath.Dir(from)}
+	}
+
+	for _, dir := range dirs {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return filepath.Abs(file)
+		}
+	}
+	return "", fmt.Errorf("Module %s not found (searched %s)", name, strings.Join(dirs, ", "))
+}
+
+// Enter marks a module as being loaded, failing if it already is (an import cycle)
+func (l *Loader) Enter(file string) error {
+	for i, loading := range l.loading {
+		if loading != file {
+			continue
+		}
+
+		cycle := []string{}
+		for _, f := range append(l.loading[i:], file) {
+			cycle = append(cycle, displayPath(f))
+		}
+		return fmt.Errorf("Import cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	l.loading = append(l.loading, file)
+	return nil
+}
+
+// Leave marks the innermost module being loaded as loaded
+func (l *Loader) Leave() {
+	l.loading = l.loading[:len(l.loading)-1]
+}
+
+// Parse reads and parses the source file of a module
+func (l *Loader) Parse(file string) (*ast.Program, error) {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read module %s: %s", displayPath(file), err)
+	}
+
+	p := parser.New(lexer.NewWithFile(string(source), displayPath(file)))
+	program := p.ParseProgram()
+
+	if errors := p.Errors(); len(errors) != 0 {
+		messages := []string{}
+		for _, err := range errors {
+			messages = append(messages, err.Error())
+		}
+		return nil, fmt.Errorf("Module %s could not be parsed:\n%s", displayPath(file), strings.Join(messages, "\n"))
+	}
+
+	return program, nil
+}
+
+// Returns a file path relative to the working directory (if possible), for messages and source positions
+func displayPath(file string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(wd, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Exports returns the names exported by a module, in order
+func Exports(program *ast.Program) []string {
+	names := []string{}
+	for _, s := range program.Statements {
+		if export, ok := s.(*ast.ExportStatement); ok {
+			names = append(names, export.Let.Name.Value)
+		}
+	}
+	return names
+}
@@ -0,0 +1,117 @@
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Writes the given files (by path relative to the directory) into a new temporary directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, source := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolve(t *testing.T) {
+	first := writeFiles(t, map[string]string{"lib/a.ape": "", "b.ape": ""})
+	second := writeFiles(t, map[string]string{"lib/a.ape": "", "c.ape": "", "lib/d.ape": ""})
+	loader := NewLoader([]string{first, second})
+
+	tests := []struct {
+		name     string
+		from     string
+		expected string
+	}{
+		{"lib/a", "main.ape", filepath.Join(first, "lib", "a.ape")},
+		{"lib/a.ape", "main.ape", filepath.Join(first, "lib", "a.ape")},
+		{"c", "main.ape", filepath.Join(second, "c.ape")},
+		{"./d", filepath.Join(second, "lib", "a.ape"), filepath.Join(second, "lib", "d.ape")},
+		{"../b", filepath.Join(first, "lib", "a.ape"), filepath.Join(first, "b.ape")},
+	}
+
+	for _, tt := range tests {
+		file, err := loader.Resolve(tt.name, tt.from)
+		if err != nil {
+			t.Fatalf("Resolve(%q) failed: %s", tt.name, err)
+		}
+		if file != tt.expected {
+			t.Errorf("Resolve(%q): expected %q, got %q", tt.name, tt.expected, file)
+		}
+	}
+
+	_, err := loader.Resolve("lib", "main.ape")
+	if err == nil {
+		t.Errorf("Expected an error resolving a directory")
+	}
+	_, err = loader.Resolve("./b", filepath.Join(second, "main.ape"))
+	if err == nil {
+		t.Errorf("Expected an error resolving a relative path outside of the importing directory")
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(PathVariable, "")
+	if path := DefaultPath(); !reflect.DeepEqual(path, []string{"."}) {
+		t.Errorf("Expected the working directory only, got %v", path)
+	}
+
+	t.Setenv(PathVariable, "a"+string(filepath.ListSeparator)+"b")
+	if path := DefaultPath(); !reflect.DeepEqual(path, []string{"a", "b", "."}) {
+		t.Errorf("Expected the APE_PATH directories and the working directory, got %v", path)
+	}
+}
+
+func TestImportCycles(t *testing.T) {
+	loader := NewLoader(nil)
+
+	for _, file := range []string{"/a.ape", "/b.ape", "/c.ape"} {
+		if err := loader.Enter(file); err != nil {
+			t.Fatalf("Enter(%q) failed: %s", file, err)
+		}
+	}
+
+	err := loader.Enter("/b.ape")
+	expected := "Import cycle: /b.ape -> /c.ape -> /b.ape"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q, got %v", expected, err)
+	}
+
+	loader.Leave()
+	loader.Leave()
+	if err := loader.Enter("/c.ape"); err != nil {
+		t.Errorf("Expected no cycle once the module was left, got %s", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"ok.ape":  "export let a = 1; let b = 2; export let c = fn() { b };",
+		"bad.ape": "let = 1;",
+	})
+	loader := NewLoader([]string{dir})
+
+	program, err := loader.Parse(filepath.Join(dir, "ok.ape"))
+	if err != nil {
+		t.Fatalf("Parse failed: %s", err)
+	}
+	if exports := Exports(program); !reflect.DeepEqual(exports, []string{"a", "c"}) {
+		t.Errorf("Expected exports [a c], got %v", exports)
+	}
+
+	_, err = loader.Parse(filepath.Join(dir, "bad.ape"))
+	if err == nil {
+		t.Errorf("Expected a parse error")
+	}
+}
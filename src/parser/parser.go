@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-
 	"github.com/ape-lang/ape/src/ast"
 	"github.com/ape-lang/ape/src/lexer"
 	"github.com/ape-lang/ape/src/token"
@@ -19,7 +17,9 @@ type Parser struct {
 	current token.Token
 	next    token.Token
 
-	errors []string
+	errors    []*ParseError
+	panicking bool // Whether the parser is recovering from an error
+	depth     int  // The brace nesting depth of the current token
 
 	prefixParsers map[token.TokenType]prefixParser
 	infixParsers  map[token.TokenType]infixParser
@@ -27,7 +27,7 @@ type Parser struct {
 
 // New creates and returns a new Parser
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{lexer: l, errors: []string{}}
+	p := &Parser{lexer: l, errors: []*ParseError{}}
 
 	// Create the prefix parsers map
 	p.prefixParsers = make(map[token.TokenType]prefixParser)
@@ -35,8 +35,10 @@ func New(l *lexer.Lexer) *Parser {
 	// Append the prefix parsers to the map
 	p.addPrefixParser(token.IDENT, p.parseIdentifier)
 	p.addPrefixParser(token.INT, p.parseIntegerLiteral)
+	p.addPrefixParser(token.FLOAT, p.parseFloatLiteral)
 	p.addPrefixParser(token.BANG, p.parsePrefixExpression)
 	p.addPrefixParser(token.MINUS, p.parsePrefixExpression)
+	p.addPrefixParser(token.TILDE, p.parsePrefixExpression)
 	p.addPrefixParser(token.TRUE, p.parseBoolean)
 	p.addPrefixParser(token.FALSE, p.parseBoolean)
 	p.addPrefixParser(token.PARENL, p.parseGroupedExpression)
@@ -45,6 +47,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.addPrefixParser(token.STRING, p.parseStringLiteral)
 	p.addPrefixParser(token.BRACKETL, p.parseArrayLiteral)
 	p.addPrefixParser(token.BRACEL, p.parseHashLiteral)
+	p.addPrefixParser(token.ILLEGAL, p.parseIllegal)
 
 	// Create the infix parsers map
 	p.infixParsers = make(map[token.TokenType]infixParser)
@@ -58,8 +61,25 @@ func New(l *lexer.Lexer) *Parser {
 	p.addInfixParser(token.NEQ, p.parseInfixExpression)
 	p.addInfixParser(token.LT, p.parseInfixExpression)
 	p.addInfixParser(token.GT, p.parseInfixExpression)
+	p.addInfixParser(token.LTE, p.parseInfixExpression)
+	p.addInfixParser(token.GTE, p.parseInfixExpression)
+	p.addInfixParser(token.PERCENT, p.parseInfixExpression)
+	p.addInfixParser(token.AND, p.parseInfixExpression)
+	p.addInfixParser(token.OR, p.parseInfixExpression)
+	p.addInfixParser(token.AMPERSAND, p.parseInfixExpression)
+	p.addInfixParser(token.PIPE, p.parseInfixExpression)
+	p.addInfixParser(token.CARET, p.parseInfixExpression)
+	p.addInfixParser(token.LSHIFT, p.parseInfixExpression)
+	p.addInfixParser(token.RSHIFT, p.parseInfixExpression)
+	p.addInfixParser(token.ASSIGN, p.parseAssignExpression)
+	p.addInfixParser(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.addInfixParser(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.addInfixParser(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.addInfixParser(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.addInfixParser(token.PERCENT_ASSIGN, p.parseAssignExpression)
 	p.addInfixParser(token.PARENL, p.parseCallExpression)
 	p.addInfixParser(token.BRACKETL, p.parseIndexExpression)
+	p.addInfixParser(token.DOT, p.parseMemberExpression)
 
 	// Reads twice so current and next are not nil
 	p.advance()
@@ -69,7 +89,7 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 // Errors returns the parser errors
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() []*ParseError {
 	return p.errors
 }
 
@@ -79,13 +99,11 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for p.current.Type != token.EOF {
-		stmt := p.parseStatement()
+		stmt := p.parseStatementAt(0)
 
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
-
-		p.advance()
 	}
 	return program
 }
@@ -94,6 +112,36 @@ func (p *Parser) ParseProgram() *ast.Program {
 func (p *Parser) advance() {
 	p.current = p.next
 	p.next = p.lexer.NextToken()
+
+	// Track the brace nesting (unbalanced closing braces are ignored)
+	switch p.current.Type {
+	case token.BRACEL:
+		p.depth++
+	case token.BRACER:
+		if p.depth > 0 {
+			p.depth--
+		}
+	}
+}
+
+// Parses the statement starting at the current token and advances past it
+// On errors, skips to the next statement without leaving the block at the given depth
+func (p *Parser) parseStatementAt(depth int) ast.Statement {
+	stmt := p.parseStatement()
+
+	if p.panicking {
+		p.synchronize(depth)
+		p.panicking = false
+
+		// Stopped on the closing brace of the enclosing block, which is left for the block to consume
+		if p.depth < depth {
+			return nil
+		}
+		stmt = nil
+	}
+
+	p.advance()
+	return stmt
 }
 
 func (p *Parser) parseStatement() ast.Statement {
@@ -102,6 +150,20 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.TRY:
+		return p.parseTryStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -125,11 +187,6 @@ func (p *Parser) isNext(t token.TokenType) bool {
 	return p.next.Type == t
 }
 
-func (p *Parser) errorNext(t token.TokenType) {
-	msg := fmt.Sprintf("Expected next token to be '%s', got '%s' instead", t, p.next.Type)
-	p.errors = append(p.errors, msg)
-}
-
 func (p *Parser) addPrefixParser(tokenType token.TokenType, fn prefixParser) {
 	p.prefixParsers[tokenType] = fn
 }
@@ -5,14 +5,30 @@ import (
 	"github.com/ape-lang/ape/src/token"
 )
 
+// Parses an index expression (`left[index]`) or a slice expression (`left[start:end]`, both bounds being optional)
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.current, Left: left}
+	tok := p.current
 	p.advance()
-	exp.Index = p.parseExpression(LOWEST)
 
+	var index ast.Expression
+	if !p.isCurrent(token.COLON) {
+		index = p.parseExpression(LOWEST)
+		if !p.isNext(token.COLON) {
+			if !p.advanceIfNext(token.BRACKETR) {
+				return nil
+			}
+			return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+		}
+		p.advance()
+	}
+
+	slice := &ast.SliceExpression{Token: tok, Left: left, Start: index}
+	if !p.isNext(token.BRACKETR) {
+		p.advance()
+		slice.End = p.parseExpression(LOWEST)
+	}
 	if !p.advanceIfNext(token.BRACKETR) {
 		return nil
 	}
-
-	return exp
+	return slice
 }
@@ -0,0 +1,24 @@
+package parser
+
+import "github.com/ape-lang/ape/src/ast"
+
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    p.current,
+		Target:   target,
+		Operator: p.current.Literal,
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	default:
+		p.addError(p.current, nil, "Invalid assignment target '%s'", target.String())
+		return nil
+	}
+
+	// Assignments are right associative, so `a = b = c` assigns `c` to both
+	p.advance()
+	expression.Value = p.parseExpression(ASSIGN - 1)
+
+	return expression
+}
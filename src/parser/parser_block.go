@@ -8,14 +8,14 @@ import (
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.current}
 	block.Statements = []ast.Statement{}
+	depth := p.depth
 	p.advance()
 
 	for !p.isCurrent(token.BRACER) && !p.isCurrent(token.EOF) {
-		stmt := p.parseStatement()
+		stmt := p.parseStatementAt(depth)
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
-		p.advance()
 	}
 
 	return block
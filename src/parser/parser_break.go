@@ -0,0 +1,22 @@
+package parser
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/token"
+)
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.current}
+	if p.isNext(token.SEMICOLON) {
+		p.advance()
+	}
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.current}
+	if p.isNext(token.SEMICOLON) {
+		p.advance()
+	}
+	return stmt
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ape-lang/ape/src/token"
+)
+
+// ParseError describes a syntax error found while parsing
+type ParseError struct {
+	Position token.Position    // Where the error occurred
+	Expected []token.TokenType // The tokens that would have been valid (if known)
+	Found    token.Token       // The offending token
+	Message  string
+}
+
+// Error returns the error message prefixed by its position
+func (e *ParseError) Error() string {
+	return e.Position.String() + ": " + e.Message
+}
+
+// Render returns the error message followed by the offending source line and a caret under the error column
+func (e *ParseError) Render(source string) string {
+	lines := strings.Split(source, "\n")
+	if !e.Position.IsValid() || e.Position.Line > len(lines) {
+		return e.Error()
+	}
+
+	line := strings.TrimRight(lines[e.Position.Line-1], "\r")
+
+	// Tabs are kept so the caret lines up with the source regardless of the tab width
+	var indent strings.Builder
+	for i, char := range []rune(line) {
+		if i >= e.Position.Column-1 {
+			break
+		}
+		if char == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+
+	return fmt.Sprintf("%s\n%s\n%s^", e.Error(), line, indent.String())
+}
+
+// Records an error unless the parser is already recovering from a previous one (avoids cascading errors)
+func (p *Parser) addError(found token.Token, expected []token.TokenType, format string, a ...interface{}) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+
+	p.errors = append(p.errors, &ParseError{
+		Position: found.Position,
+		Expected: expected,
+		Found:    found,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+func (p *Parser) errorNext(t token.TokenType) {
+	p.addError(p.next, []token.TokenType{t}, "Expected next token to be '%s', got '%s' instead", t, p.next.Type)
+}
+
+// Skips tokens until the end of the current statement (without leaving the block at the given depth)
+func (p *Parser) synchronize(depth int) {
+	for !p.isCurrent(token.EOF) && p.depth >= depth {
+		if p.depth == depth {
+			if p.isCurrent(token.SEMICOLON) {
+				return
+			}
+			if isStatementStart(p.next.Type) || p.isNext(token.BRACER) {
+				return
+			}
+		}
+		p.advance()
+	}
+}
+
+// Checks whether a token type can only appear at the start of a statement
+func isStatementStart(t token.TokenType) bool {
+	switch t {
+	case token.LET, token.RETURN, token.WHILE, token.FOR, token.BREAK, token.CONTINUE, token.TRY, token.IMPORT, token.EXPORT:
+		return true
+	default:
+		return false
+	}
+}
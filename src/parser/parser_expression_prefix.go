@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-
 	"github.com/ape-lang/ape/src/ast"
 	"github.com/ape-lang/ape/src/token"
 )
@@ -20,6 +18,5 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 }
 
 func (p *Parser) prefixParserError(t token.TokenType) {
-	msg := fmt.Sprintf("No prefix parser found for %s", t)
-	p.errors = append(p.errors, msg)
+	p.addError(p.current, nil, "No prefix parser found for %s", t)
 }
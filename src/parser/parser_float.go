@@ -0,0 +1,20 @@
+package parser
+
+import (
+	"strconv"
+
+	"github.com/ape-lang/ape/src/ast"
+)
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.current}
+	value, err := strconv.ParseFloat(p.current.Literal, 64)
+
+	if err != nil {
+		p.addError(p.current, nil, "'%q' could not be parsed into a float", p.current.Literal)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/token"
+)
+
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	stmt := &ast.ForStatement{Token: p.current}
+	if !p.advanceIfNext(token.PARENL) {
+		return nil
+	}
+	if !p.advanceIfNext(token.LET) {
+		return nil
+	}
+	if !p.advanceIfNext(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+	if !p.advanceIfNext(token.IN) {
+		return nil
+	}
+
+	p.advance()
+	stmt.Iterable = p.parseExpression(LOWEST)
+	if !p.advanceIfNext(token.PARENR) {
+		return nil
+	}
+
+	if !p.advanceIfNext(token.BRACEL) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+	if p.isNext(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
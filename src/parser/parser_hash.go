@@ -6,8 +6,7 @@ import (
 )
 
 func (p *Parser) parseHashLiteral() ast.Expression {
-	hash := &ast.HashLiteral{Token: p.current}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
+	hash := &ast.HashLiteral{Token: p.current, Pairs: []ast.HashLiteralPair{}}
 
 	for !p.isNext(token.BRACER) {
 		p.advance()
@@ -19,7 +18,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 		p.advance()
 		value := p.parseExpression(LOWEST)
-		hash.Pairs[key] = value
+		hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Key: key, Value: value})
 
 		if !p.isNext(token.BRACER) && !p.advanceIfNext(token.COMMA) {
 			return nil
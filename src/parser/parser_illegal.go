@@ -0,0 +1,9 @@
+package parser
+
+import "github.com/ape-lang/ape/src/ast"
+
+// Reports the error described by an illegal token produced by the lexer
+func (p *Parser) parseIllegal() ast.Expression {
+	p.addError(p.current, nil, "%s", p.current.Literal)
+	return nil
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/token"
+)
+
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.current}
+	if p.depth > 0 {
+		p.addError(p.current, nil, "Imports are only allowed at the top level")
+		return nil
+	}
+
+	if p.isNext(token.BRACEL) {
+		p.advance()
+		stmt.Names = p.parseImportNames()
+		if stmt.Names == nil {
+			return nil
+		}
+
+		// `from` is not a keyword, so it can still be used as an identifier
+		if !p.isNext(token.IDENT) || p.next.Literal != "from" {
+			p.addError(p.next, []token.TokenType{token.IDENT}, "Expected 'from' after the imported names, got '%s' instead", p.next.Literal)
+			return nil
+		}
+		p.advance()
+	}
+
+	if !p.advanceIfNext(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.current.Literal
+
+	if stmt.Names == nil {
+		// The module is bound to the last element of its path, without the extension
+		name := strings.TrimSuffix(path.Base(stmt.Path), ".ape")
+		if !isIdentifier(name) {
+			p.addError(p.current, nil, "Cannot name the module '%s' (its file name is not an identifier)", stmt.Path)
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.current, Value: name}
+	}
+
+	if p.isNext(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
+
+// Parses the names in `{ a, b }`, the current token being the opening brace
+func (p *Parser) parseImportNames() []*ast.Identifier {
+	names := []*ast.Identifier{}
+
+	for {
+		if !p.advanceIfNext(token.IDENT) {
+			return nil
+		}
+		names = append(names, &ast.Identifier{Token: p.current, Value: p.current.Literal})
+
+		if !p.isNext(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+
+	if !p.advanceIfNext(token.BRACER) {
+		return nil
+	}
+	return names
+}
+
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	stmt := &ast.ExportStatement{Token: p.current}
+	if p.depth > 0 {
+		p.addError(p.current, nil, "Exports are only allowed at the top level")
+		return nil
+	}
+
+	if !p.advanceIfNext(token.LET) {
+		return nil
+	}
+
+	stmt.Let = p.parseLetStatement()
+	if stmt.Let == nil {
+		return nil
+	}
+
+	// The doc comment is attached to the export keyword
+	stmt.Let.Doc = stmt.Token.Doc
+
+	return stmt
+}
+
+// Checks whether a name can be used as an identifier
+func isIdentifier(name string) bool {
+	if name == "" || token.LookupIdent(name) != token.IDENT {
+		return false
+	}
+	for i, char := range name {
+		isDigit := '0' <= char && char <= '9'
+		if !unicode.IsLetter(char) && char != '_' && (i == 0 || !isDigit) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/ape-lang/ape/src/ast"
@@ -12,8 +11,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	value, err := strconv.ParseInt(p.current.Literal, 0, 64)
 
 	if err != nil {
-		msg := fmt.Sprintf("'%q' could not be parsed into an integer", p.current.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.current, nil, "'%q' could not be parsed into an integer", p.current.Literal)
 		return nil
 	}
 
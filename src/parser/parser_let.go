@@ -6,7 +6,7 @@ import (
 )
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
-	stmt := &ast.LetStatement{Token: p.current}
+	stmt := &ast.LetStatement{Token: p.current, Doc: p.current.Doc}
 	if !p.advanceIfNext(token.IDENT) {
 		return nil
 	}
@@ -18,6 +18,9 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	p.advance()
 	stmt.Value = p.parseExpression(LOWEST)
+	if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+		fn.Name = stmt.Name.Value
+	}
 	if p.isNext(token.SEMICOLON) {
 		p.advance()
 	}
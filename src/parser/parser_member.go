@@ -0,0 +1,16 @@
+package parser
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/token"
+)
+
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.current, Object: object}
+	if !p.advanceIfNext(token.IDENT) {
+		return nil
+	}
+
+	exp.Member = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+	return exp
+}
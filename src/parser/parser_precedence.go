@@ -6,10 +6,13 @@ import "github.com/ape-lang/ape/src/token"
 const (
 	_ int = iota // Declares the constants in the block as incrementing ints
 	LOWEST
+	ASSIGN
+	LOGICAL_OR
+	LOGICAL_AND
 	EQUALS
 	LESSGREATER
-	SUM
-	PRODUCT
+	SUM     // Also includes the bitwise or/xor operators
+	PRODUCT // Also includes the modulo, bitwise and and shift operators
 	PREFIX
 	CALL
 	INDEX
@@ -17,16 +20,33 @@ const (
 
 // Precedence mapping to token
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NEQ:      EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.PARENL:   CALL,
-	token.BRACKETL: INDEX,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.PERCENT_ASSIGN:  ASSIGN,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.EQ:              EQUALS,
+	token.NEQ:             EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LTE:             LESSGREATER,
+	token.GTE:             LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.PIPE:            SUM,
+	token.CARET:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.AMPERSAND:       PRODUCT,
+	token.LSHIFT:          PRODUCT,
+	token.RSHIFT:          PRODUCT,
+	token.PARENL:          CALL,
+	token.BRACKETL:        INDEX,
+	token.DOT:             INDEX,
 }
 
 func precedence(t token.Token) int {
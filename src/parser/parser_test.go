@@ -138,6 +138,37 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	input := "3.14;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Expected 1 Statement, got %d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected first Statement to be *ast.ExpressionStatement, got %T", program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("Expected Expression to be *ast.FloatLiteral, got %T", stmt.Expression)
+	}
+
+	if literal.Value != 3.14 {
+		t.Errorf("Expected Value to be %f, got %f", 3.14, literal.Value)
+	}
+
+	if literal.TokenLiteral() != "3.14" {
+		t.Errorf("Expected TokenLiteral to be %s, got %s", "3.14", literal.TokenLiteral())
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	input := "true;"
 
@@ -308,6 +339,298 @@ func TestIfExpression(t *testing.T) {
 	}
 }
 
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < y) { x }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Expected %d Statements, got %d", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("Expected first Statement to be *ast.WhileStatement, got %T", program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("Expected Body to be 1 Statement, got %d", len(stmt.Body.Statements))
+	}
+
+	body, ok := stmt.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Expected first Statement to be *ast.ExpressionStatement, got %T", stmt.Body.Statements[0])
+	}
+
+	testIdentifier(t, body.Expression, "x")
+}
+
+func TestForStatement(t *testing.T) {
+	input := `for (let x in xs) { if (x) { break; } continue; }; 5`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("Expected %d Statements, got %d", 2, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("Expected first Statement to be *ast.ForStatement, got %T", program.Statements[0])
+	}
+
+	if !testIdentifier(t, stmt.Name, "x") || !testIdentifier(t, stmt.Iterable, "xs") {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 2 {
+		t.Fatalf("Expected Body to be 2 Statements, got %d", len(stmt.Body.Statements))
+	}
+
+	if _, ok := stmt.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Fatalf("Expected second Statement to be *ast.ContinueStatement, got %T", stmt.Body.Statements[1])
+	}
+
+	expected := "for(x in xs) ifx break;continue;"
+	if stmt.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, stmt.String())
+	}
+}
+
+func TestTryStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { f() } catch (e) { g(e) }", "try f() catch(e) g(e)"},
+		{"try { f() } finally { g() };", "try f() finally g()"},
+		{"try { f() } catch (err) { g(err) } finally { h() }", "try f() catch(err) g(err) finally h()"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("Expected %d Statements, got %d", 1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.TryStatement)
+		if !ok {
+			t.Fatalf("Expected Statement to be *ast.TryStatement, got %T", program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestInvalidTryStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { f() } 5", "1:13: Expected 'catch' or 'finally' after the try block, got 'INT' instead"},
+		{"try { f() } catch { g() }", "1:19: Expected next token to be '(', got '{' instead"},
+		{"try { f() } catch (1) { g() }", "1:20: Expected next token to be 'IDENT', got 'INT' instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("Expected 1 error for %q, got %d", tt.input, len(errors))
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("Expected error %q, got %q", tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestImportStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		names    []string
+		expected string
+	}{
+		{`import "lib/reduce"`, "reduce", nil, `import "lib/reduce";`},
+		{`import "./utils.ape";`, "utils", nil, `import "./utils.ape";`},
+		{`import { reduce } from "lib/reduce";`, "", []string{"reduce"}, `import { reduce } from "lib/reduce";`},
+		{`import { a, b } from "./ab"`, "", []string{"a", "b"}, `import { a, b } from "./ab";`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("Expected Statement to be *ast.ImportStatement, got %T", program.Statements[0])
+		}
+
+		if tt.names == nil && (stmt.Name == nil || stmt.Name.Value != tt.name) {
+			t.Errorf("Expected the module to be bound to %q, got %v", tt.name, stmt.Name)
+		}
+		if len(stmt.Names) != len(tt.names) {
+			t.Fatalf("Expected %d imported names, got %d", len(tt.names), len(stmt.Names))
+		}
+		for i, name := range tt.names {
+			testIdentifier(t, stmt.Names[i], name)
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestExportStatements(t *testing.T) {
+	input := "// Adds numbers\nexport let add = fn(a, b) { a + b };"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("Expected Statement to be *ast.ExportStatement, got %T", program.Statements[0])
+	}
+
+	if stmt.Let.Name.Value != "add" || stmt.Let.Doc != "Adds numbers" {
+		t.Errorf("Expected the export of add (documented), got %q (%q)", stmt.Let.Name.Value, stmt.Let.Doc)
+	}
+
+	expected := "export let add = fn(a, b) (a + b);"
+	if stmt.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, stmt.String())
+	}
+}
+
+func TestInvalidModuleStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`if (true) { import "lib/reduce" }`, "1:13: Imports are only allowed at the top level"},
+		{`fn() { export let x = 1 }`, "1:8: Exports are only allowed at the top level"},
+		{`import "lib/my-module"`, "1:8: Cannot name the module 'lib/my-module' (its file name is not an identifier)"},
+		{`import { a } "lib/a"`, "1:14: Expected 'from' after the imported names, got 'lib/a' instead"},
+		{`export fn() {}`, "1:8: Expected next token to be 'LET', got 'FUNCTION' instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("Expected an error for %q, got none", tt.input)
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("Expected error %q, got %q", tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestMemberExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"m.x", "(m.x)"},
+		{"m.f(1)", "(m.f)(1)"},
+		{"a.b.c", "((a.b).c)"},
+		{"-m.x * 2", "((-(m.x)) * 2)"},
+		{"m.xs[0]", "((m.xs)[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		target   string
+		operator string
+		value    string
+	}{
+		{"x = 5;", "x", "=", "5"},
+		{"x -= y;", "x", "-=", "y"},
+		{"xs[0] *= 2;", "(xs[0])", "*=", "2"},
+		{`h["k"] /= 2;`, "(h[k])", "/=", "2"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("Expected Expression to be *ast.AssignExpression, got %T", stmt.Expression)
+		}
+
+		if exp.Target.String() != tt.target || exp.Operator != tt.operator || exp.Value.String() != tt.value {
+			t.Errorf("Expected %s %s %s, got %s %s %s",
+				tt.target, tt.operator, tt.value, exp.Target, exp.Operator, exp.Value)
+		}
+	}
+}
+
+func TestInvalidAssignTargets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 = 2;", "1:3: Invalid assignment target '1'"},
+		{"f() = 2;", "1:5: Invalid assignment target 'f()'"},
+		{"a == b = c;", "1:8: Invalid assignment target '(a == b)'"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("Expected 1 error for %q, got %d", tt.input, len(errors))
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("Expected error %q, got %q", tt.expected, errors[0].Error())
+		}
+	}
+}
+
 func TestIfElseExpression(t *testing.T) {
 	input := `if (x < y) { x } else { y }`
 	l := lexer.New(input)
@@ -572,6 +895,50 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a < b && c != d || !e",
+			"(((a < b) && (c != d)) || (!e))",
+		},
+		{
+			"a + b % c",
+			"(a + (b % c))",
+		},
+		{
+			"a | b & c ^ d",
+			"((a | (b & c)) ^ d)",
+		},
+		{
+			"1 << 2 + 3 >> 1",
+			"((1 << 2) + (3 >> 1))",
+		},
+		{
+			"~a & b",
+			"((~a) & b)",
+		},
+		{
+			"a = b = c",
+			"(a = (b = c))",
+		},
+		{
+			"a += b * c || d",
+			"(a += ((b * c) || d))",
+		},
+		{
+			"a[i + 1] %= f(x)",
+			"((a[(i + 1)]) %= f(x))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -652,6 +1019,31 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"s[1:2]", "(s[1:2])"},
+		{"s[a + 1:]", "(s[(a + 1):])"},
+		{"s[:len(s) - 1]", "(s[:(len(s) - 1)])"},
+		{"s[:]", "(s[:])"},
+		{"s[1:][0]", "((s[1:])[0])"},
+		{`s[{"a": 1}["a"]:]`, "(s[({a:1}[a]):])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
 func TestParsingHashLiteralsStringKeys(t *testing.T) {
 	input := `{"one": 1, "two": 2, "three": 3}`
 	l := lexer.New(input)
@@ -676,14 +1068,14 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		"three": 3,
 	}
 
-	for key, value := range hash.Pairs {
-		literal, ok := key.(*ast.StringLiteral)
+	for _, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
 		if !ok {
-			t.Errorf("Expected Key to be *ast.StringLiteral, got %T", key)
+			t.Errorf("Expected Key to be *ast.StringLiteral, got %T", pair.Key)
 		}
 
 		expectedValue := expected[literal.String()]
-		testIntegerLiteral(t, value, expectedValue)
+		testIntegerLiteral(t, pair.Value, expectedValue)
 	}
 }
 
@@ -736,10 +1128,10 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		},
 	}
 
-	for key, value := range hash.Pairs {
-		literal, ok := key.(*ast.StringLiteral)
+	for _, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
 		if !ok {
-			t.Errorf("Expected Key to be *ast.StringLiteral, got %T", key)
+			t.Errorf("Expected Key to be *ast.StringLiteral, got %T", pair.Key)
 			continue
 		}
 
@@ -749,9 +1141,194 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 			continue
 		}
 
-		testFunc(value)
+		testFunc(pair.Value)
 	}
 }
+
+func TestParserErrorPositions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let x 5;", "main.ape:1:7: Expected next token to be '=', got 'INT' instead"},
+		{"let x = 5;\nlet = 10;", "main.ape:2:5: Expected next token to be 'IDENT', got '=' instead"},
+		{"1 +\n  ;", "main.ape:2:3: No prefix parser found for ;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewWithFile(tt.input, "main.ape")
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("Expected parser errors for %q, got none", tt.input)
+		}
+
+		if errors[0].Error() != tt.expectedError {
+			t.Errorf("Expected first error to be %q, got %q", tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+	}{
+		{"let x 5; let y = 10;", []string{"1:7: Expected next token to be '=', got 'INT' instead"}},
+		{
+			"let = 1; let y = ; let z = 3;",
+			[]string{
+				"1:5: Expected next token to be 'IDENT', got '=' instead",
+				"1:18: No prefix parser found for ;",
+			},
+		},
+		{
+			"let f = fn(x) { let = x; x + 1 }; let g = (1 + 2;",
+			[]string{
+				"1:21: Expected next token to be 'IDENT', got '=' instead",
+				"1:49: Expected next token to be ')', got ';' instead",
+			},
+		},
+		{"if (x { 1 } else { 2 }; 5 +;", []string{
+			"1:7: Expected next token to be ')', got '{' instead",
+			"1:28: No prefix parser found for ;",
+		}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("Expected %d errors for %q, got %d: %q", len(tt.expectedErrors), tt.input, len(errors), errors)
+			continue
+		}
+
+		for i, expected := range tt.expectedErrors {
+			if errors[i].Error() != expected {
+				t.Errorf("Expected error %d to be %q, got %q", i, expected, errors[i])
+			}
+		}
+	}
+}
+
+func TestParserErrorRecoveryKeepsValidStatements(t *testing.T) {
+	l := lexer.New("let a = 1; let = 2; let b = 3; fn() { let = 4; 5 }; let c = 6;")
+	p := New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %q", len(p.Errors()), p.Errors())
+	}
+
+	expected := []string{"let a = 1;", "let b = 3;", "fn() 5", "let c = 6;"}
+	if len(program.Statements) != len(expected) {
+		t.Fatalf("Expected %d statements, got %d", len(expected), len(program.Statements))
+	}
+
+	for i, stmt := range program.Statements {
+		if stmt.String() != expected[i] {
+			t.Errorf("Expected statement %d to be %q, got %q", i, expected[i], stmt.String())
+		}
+	}
+}
+
+func TestParseErrorRender(t *testing.T) {
+	source := "let a = 1;\n\tlet = 2;"
+	l := lexer.NewWithFile(source, "main.ape")
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) != 1 {
+		t.Fatalf("Expected 1 error, got %d", len(p.Errors()))
+	}
+
+	expected := "main.ape:2:6: Expected next token to be 'IDENT', got '=' instead\n\tlet = 2;\n\t    ^"
+	result := p.Errors()[0].Render(source)
+
+	if result != expected {
+		t.Errorf("Bad Render() result. Expected %q, got %q", expected, result)
+	}
+}
+
+func TestIllegalTokenErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let x = 5 @ 1;", "1:11: Unexpected character '@'"},
+		{"let x = 5;\n/* unterminated", "2:1: Unterminated block comment"},
+		{`let s = "abc`, "1:9: Unterminated string"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("Expected 1 error for %q, got %d: %q", tt.input, len(errors), errors)
+		}
+
+		if errors[0].Error() != tt.expectedError {
+			t.Errorf("Expected error to be %q, got %q", tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestLetFunctionNames(t *testing.T) {
+	input := `let add = fn(a, b) { a + b }; let x = 5; fn() {};`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	add := program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if add.Name != "add" {
+		t.Errorf("Expected function name to be %q, got %q", "add", add.Name)
+	}
+
+	anonymous := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if anonymous.Name != "" {
+		t.Errorf("Expected anonymous function to have no name, got %q", anonymous.Name)
+	}
+}
+
+func TestLetStatementDoc(t *testing.T) {
+	input := `
+		// sums two numbers
+		let sum = fn(a, b) { a + b }; // not a doc comment
+		let x = 1;
+	`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("Expected 2 Statements, got %d", len(program.Statements))
+	}
+
+	expected := []string{"sums two numbers", ""}
+	for i, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("Expected *ast.LetStatement, got %T", stmt)
+		}
+
+		if let.Doc != expected[i] {
+			t.Errorf("Expected Doc to be %q, got %q", expected[i], let.Doc)
+		}
+	}
+}
+
 func checkParserErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
 	if len(errors) == 0 {
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/token"
+)
+
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	stmt := &ast.TryStatement{Token: p.current}
+	if !p.advanceIfNext(token.BRACEL) {
+		return nil
+	}
+	stmt.Body = p.parseBlockStatement()
+
+	if p.isNext(token.CATCH) {
+		p.advance()
+		if !p.advanceIfNext(token.PARENL) {
+			return nil
+		}
+		if !p.advanceIfNext(token.IDENT) {
+			return nil
+		}
+
+		stmt.Name = &ast.Identifier{Token: p.current, Value: p.current.Literal}
+		if !p.advanceIfNext(token.PARENR) {
+			return nil
+		}
+
+		if !p.advanceIfNext(token.BRACEL) {
+			return nil
+		}
+		stmt.Catch = p.parseBlockStatement()
+	}
+
+	if p.isNext(token.FINALLY) {
+		p.advance()
+		if !p.advanceIfNext(token.BRACEL) {
+			return nil
+		}
+		stmt.Finally = p.parseBlockStatement()
+	}
+
+	if stmt.Catch == nil && stmt.Finally == nil {
+		p.addError(p.next, []token.TokenType{token.CATCH, token.FINALLY}, "Expected 'catch' or 'finally' after the try block, got '%s' instead", p.next.Type)
+		return nil
+	}
+
+	if p.isNext(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
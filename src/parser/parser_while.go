@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"github.com/ape-lang/ape/src/ast"
+	"github.com/ape-lang/ape/src/token"
+)
+
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{Token: p.current}
+	if !p.advanceIfNext(token.PARENL) {
+		return nil
+	}
+	p.advance()
+
+	stmt.Condition = p.parseExpression(LOWEST)
+	if !p.advanceIfNext(token.PARENR) {
+		return nil
+	}
+
+	if !p.advanceIfNext(token.BRACEL) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+	if p.isNext(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
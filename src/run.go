@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ape-lang/ape/src/compiler/compiler"
+	"github.com/ape-lang/ape/src/compiler/symbols"
+	"github.com/ape-lang/ape/src/compiler/vm"
+	"github.com/ape-lang/ape/src/data"
+	"github.com/ape-lang/ape/src/lexer"
+	"github.com/ape-lang/ape/src/parser"
+)
+
+// run executes a script file (or a compiled file), exposing the script args as the `args` global
+func run(path string, args []string, optimization int) int {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read file:\n Error: %s\n", err)
+		return 1
+	}
+
+	var bytecode *compiler.Bytecode
+	if bytes.HasPrefix(source, []byte(compiler.Magic)) {
+		bytecode = &compiler.Bytecode{}
+		err = bytecode.UnmarshalBinary(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not load compiled file:\n Error: %s\n", err)
+			return 1
+		}
+	} else {
+		var ok bool
+		bytecode, ok = compile(path, string(source), optimization)
+		if !ok {
+			return 1
+		}
+	}
+
+	_, argsIndex := globalSymbols()
+	globals := make([]data.Data, argsIndex+1)
+	globals[argsIndex] = argsArray(args)
+
+	machine := vm.NewWithGlobals(bytecode, globals)
+	err = machine.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Execution failed:\n Error: %s\n", err)
+		if runtimeErr, ok := err.(*vm.RuntimeError); ok {
+			fmt.Fprintln(os.Stderr, " Stack trace:")
+			for _, line := range strings.Split(runtimeErr.StackTrace(), "\n") {
+				fmt.Fprintf(os.Stderr, "\t%s\n", line)
+			}
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// compile lexes, parses and compiles a script at the given optimization level, printing the errors (if any)
+func compile(path string, source string, optimization int) (*compiler.Bytecode, bool) {
+	l := lexer.NewWithFile(source, path)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		fmt.Fprintln(os.Stderr, "Input could not be parsed!")
+		fmt.Fprintln(os.Stderr, " Errors:")
+		for _, err := range p.Errors() {
+			for _, line := range strings.Split(err.Render(source), "\n") {
+				fmt.Fprintf(os.Stderr, "\t%s\n", line)
+			}
+		}
+		return nil, false
+	}
+
+	symbolTable, _ := globalSymbols()
+	comp := compiler.NewWithState(symbolTable, []data.Data{})
+	comp.SetOptimization(optimization)
+	err := comp.Compile(program)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Compilation failed:\n Error: %s\n", err)
+		return nil, false
+	}
+
+	return comp.Bytecode(), true
+}
+
+// Creates the symbols of the scripts, the builtins and the `args` global, whose index is returned
+// The symbols are the same for every script, so compiled files find `args` at the same index
+func globalSymbols() (*symbols.SymbolTable, int) {
+	symbolTable := symbols.New()
+	for i, v := range data.Builtins {
+		symbolTable.DefineBuiltin(i, v.Name)
+	}
+	return symbolTable, symbolTable.Define("args").Index
+}
+
+// Converts the command line args into an array of strings
+func argsArray(args []string) *data.Array {
+	elements := make([]data.Data, len(args))
+	for i, arg := range args {
+		elements[i] = &data.String{Value: arg}
+	}
+	return data.NewArray(elements)
+}
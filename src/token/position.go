@@ -0,0 +1,23 @@
+package token
+
+import "fmt"
+
+// Position represents a location in the source (line and column are 1-based)
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsValid reports whether the position points to an actual location in the source
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position formatted as 'file:line:col' (or 'line:col' if the file is unknown)
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
@@ -7,6 +7,7 @@ const (
 	LET      = "LET"
 
 	INT   = "INT"
+	FLOAT = "FLOAT"
 	TRUE  = "TRUE"
 	FALSE = "FALSE"
 
@@ -14,24 +15,57 @@ const (
 	ELSE   = "ELSE"
 	RETURN = "RETURN"
 
-	ASSIGN   = "="
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+
+	TRY     = "TRY"
+	CATCH   = "CATCH"
+	FINALLY = "FINALLY"
+
+	IMPORT = "IMPORT"
+	EXPORT = "EXPORT"
+
+	ASSIGN          = "="
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
+
 	PLUS     = "+"
 	MINUS    = "-"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
 
 	BANG = "!"
 	LT   = "<"
 	GT   = ">"
+	LTE  = "<="
+	GTE  = ">="
 	EQ   = "=="
 	NEQ  = "!="
 
+	AND = "&&"
+	OR  = "||"
+
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	LSHIFT    = "<<"
+	RSHIFT    = ">>"
+
 	PARENL    = "("
 	PARENR    = ")"
 	BRACEL    = "{"
 	BRACER    = "}"
 	BRACKETL  = "["
 	BRACKETR  = "]"
+	DOT       = "."
 	COMMA     = ","
 	COLON     = ":"
 	SEMICOLON = ";"
@@ -45,11 +79,14 @@ const (
 type TokenType string
 
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type     TokenType
+	Literal  string
+	Position Position // The position of the first character of the token
+	End      Position // The position right after the last character of the token
+	Doc      string   // The line comments right before the token
 }
 
-func New(tokenType TokenType, char byte) Token {
+func New(tokenType TokenType, char rune) Token {
 	return Token{Type: tokenType, Literal: string(char)}
 }
 
@@ -63,6 +100,19 @@ var keywords = map[string]TokenType{
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+
+	"import": IMPORT,
+	"export": EXPORT,
 }
 
 func LookupIdent(ident string) TokenType {